@@ -4,8 +4,10 @@ import (
 	"database/sql"
 	"log"
 	"net/http"
+	"time"
 
 	_ "github.com/go-sql-driver/mysql"
+	"github.com/atharv3903/graphion/internal/algo"
 	"github.com/atharv3903/graphion/internal/api"
 	"github.com/atharv3903/graphion/internal/config"
 )
@@ -20,6 +22,53 @@ func main() {
 	defer db.Close()
 
 	srv := api.New(db)
+	srv.Alternatives.MaxOverlap = cfg.AlternativesMaxOverlap
+	srv.Alternatives.MaxStretch = cfg.AlternativesMaxStretch
+	srv.MatrixWorkers = cfg.MatrixWorkers
+	srv.MaxExplored = cfg.MaxExplored
+	srv.SearchTimeout = cfg.SearchTimeout
+	if cfg.InMemory {
+		if err := srv.EnableInMemory(); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if cfg.Turns {
+		if err := srv.EnableTurns(cfg.UTurnSeconds); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if cfg.Traffic {
+		loc, err := time.LoadLocation(cfg.TrafficTZ)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := srv.EnableTraffic(loc); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if cfg.CH {
+		m, err := algo.ParseMetric(cfg.CHMetric)
+		if err != nil {
+			log.Fatal(err)
+		}
+		srv.EnableCH(m)
+	}
+	if cfg.CRP {
+		m, err := algo.ParseMetric(cfg.CRPMetric)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := srv.EnableCRP(m); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if cfg.ALTPath != "" {
+		m, err := algo.ParseMetric(cfg.ALTMetric)
+		if err != nil {
+			log.Fatal(err)
+		}
+		srv.EnableALT(cfg.ALTPath, cfg.ALTLandmarks, cfg.ALTStrategy, m)
+	}
 
 	log.Println("GRAPHION listening on", cfg.Addr)
 	log.Fatal(http.ListenAndServe(cfg.Addr, srv.Mux))
@@ -0,0 +1,203 @@
+package algo
+
+import (
+	"container/heap"
+	"sort"
+
+	"github.com/atharv3903/graphion/internal/model"
+)
+
+// Only the landmarks that bound the source best are consulted during a
+// query; evaluating all of them at every node costs more than it saves.
+const altActiveLandmarks = 4
+
+// altMaxDetour caps how many nodes outside the tables ALT walks through to
+// reach the ones inside from a source or target that is not in them.
+const altMaxDetour = 64
+
+// altTarget is a table node the target can be reached from, and the least
+// cost of doing so without passing through another table node.
+type altTarget struct {
+	node int32
+	cost int
+}
+
+// ALTHeuristic is the landmark lower bound on the remaining cost to dst. It
+// is in the units of the metric the tables were built for. Nodes outside
+// the tables, e.g. added after preprocessing, get 0.
+func ALTHeuristic(l *Landmarks, src, dst int64) Heuristic {
+	t, ok := l.index[dst]
+	if !ok {
+		return ZeroHeuristic
+	}
+	s, ok := l.index[src]
+	if !ok {
+		s = -1
+	}
+	return l.heuristic(s, dst, []altTarget{{t, 0}})
+}
+
+// heuristic bounds the cost to dst from a table node v by the least of
+// bound(v, t) + cost over the targets. The active landmarks are the ones
+// bounding source s (-1 for none) to the nearest target best.
+func (l *Landmarks) heuristic(s int32, dst int64, targets []altTarget) Heuristic {
+	nearest := targets[0]
+	for _, t := range targets[1:] {
+		if t.cost < nearest.cost {
+			nearest = t
+		}
+	}
+	active := make([]int, len(l.Nodes))
+	for i := range active {
+		active[i] = i
+	}
+	if s >= 0 {
+		sort.Slice(active, func(a, b int) bool {
+			return l.boundVia(active[a], s, nearest.node) > l.boundVia(active[b], s, nearest.node)
+		})
+	}
+	active = active[:min(len(active), altActiveLandmarks)]
+
+	return func(n int64) (int, error) {
+		if n == dst {
+			return 0, nil
+		}
+		v, ok := l.index[n]
+		if !ok {
+			return 0, nil
+		}
+		best := -1
+		for _, t := range targets {
+			b := 0
+			for _, i := range active {
+				b = max(b, l.boundVia(i, v, t.node))
+			}
+			if best < 0 || b+t.cost < best {
+				best = b + t.cost
+			}
+		}
+		return best, nil
+	}
+}
+
+// ALT is A* with landmark bounds instead of coordinates, so it needs no
+// node positions at all. cost must be the metric the tables were built for.
+// A source or target outside the tables, such as a virtual node partway
+// along a road, is bounded through the table nodes next to it.
+func ALT(g Graph, l *Landmarks, src, dst int64, cost func(int, int) int) ([]int64, int, int, error) {
+	h := ALTHeuristic(l, src, dst)
+	_, srcIn := l.index[src]
+	_, dstIn := l.index[dst]
+	if !srcIn || !dstIn {
+		targets, err := l.reach(g, dst, true, cost)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		if len(targets) > 0 {
+			s := int32(-1)
+			if sources, err := l.reach(g, src, false, cost); err != nil {
+				return nil, 0, 0, err
+			} else if len(sources) > 0 {
+				first := sources[0]
+				for _, x := range sources[1:] {
+					if x.cost < first.cost {
+						first = x
+					}
+				}
+				s = first.node
+			}
+			h = l.heuristic(s, dst, targets)
+		}
+	}
+	return AStar(g, src, dst, cost, h)
+}
+
+// reach finds the table nodes nearest n: the first ones met walking out of
+// n, or into it when back is set, through nodes outside the tables only,
+// each with the least cost of that walk. It gives up and returns nil after
+// altMaxDetour such nodes.
+func (l *Landmarks) reach(g Graph, n int64, back bool, cost func(int, int) int) ([]altTarget, error) {
+	if v, ok := l.index[n]; ok {
+		return []altTarget{{v, 0}}, nil
+	}
+	dist := map[int64]int{n: 0}
+	done := map[int64]bool{}
+	best := map[int32]int{}
+	q := &pq{{node: n}}
+	for q.Len() > 0 {
+		cur := heap.Pop(q).(pqItem)
+		if done[cur.node] {
+			continue
+		}
+		done[cur.node] = true
+		if len(done) > altMaxDetour {
+			return nil, nil
+		}
+		var edges []model.Edge
+		var err error
+		if back {
+			edges, err = g.InNeighbors(cur.node)
+		} else {
+			edges, err = g.Neighbors(cur.node)
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range edges {
+			next := e.Dst
+			if back {
+				next = e.Src
+			}
+			d := cur.dist + cost(e.DistM, e.Speed)
+			if v, ok := l.index[next]; ok {
+				if old, seen := best[v]; !seen || d < old {
+					best[v] = d
+				}
+				continue
+			}
+			if old, seen := dist[next]; !seen || d < old {
+				dist[next] = d
+				heap.Push(q, pqItem{node: next, dist: d})
+			}
+		}
+	}
+	out := make([]altTarget, 0, len(best))
+	for v, c := range best {
+		out = append(out, altTarget{v, c})
+	}
+	return out, nil
+}
+
+// ALTRouter serves algo=alt for one metric from the tables Tables returns.
+// While there are none, or they are stale, it answers with Dijkstra.
+type ALTRouter struct {
+	Metric Metric
+	Tables func() *Landmarks
+}
+
+func (r ALTRouter) Name() string { return "alt" }
+
+func (r ALTRouter) Capabilities() Capabilities {
+	return Capabilities{
+		Metrics:       []string{r.Metric.Name},
+		Profiles:      []string{DefaultProfile.Name},
+		Preprocessing: true,
+		Ready:         r.usable() != nil,
+	}
+}
+
+func (r ALTRouter) Route(g Graph, q Query) (Result, error) {
+	lm := r.usable()
+	if lm == nil {
+		return dijkstraRouter{}.Route(g, q)
+	}
+	path, total, explored, err := ALT(g, lm, q.Src, q.Dst, q.Metric.Cost)
+	return Result{Path: path, Total: total, Explored: explored}, err
+}
+
+func (r ALTRouter) usable() *Landmarks {
+	if lm := r.Tables(); lm != nil && !lm.Stale() {
+		return lm
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package algo
+
+import (
+	"math/rand"
+	"path/filepath"
+	"testing"
+)
+
+func TestALTMatchesDijkstra(t *testing.T) {
+	g := testGrid(25, 3)
+	for _, strategy := range []string{LandmarksFarthest, LandmarksAvoid} {
+		for _, m := range []Metric{Distance, TravelTime} {
+			lm, err := BuildLandmarks(m, g.edges(), 6, strategy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rnd := rand.New(rand.NewSource(4))
+			for range 150 {
+				src, dst := g.ids[rnd.Intn(len(g.ids))], g.ids[rnd.Intn(len(g.ids))]
+				_, want, _, _ := Dijkstra(g, src, dst, m.Cost)
+				path, got, _, err := ALT(g, lm, src, dst, m.Cost)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got != want {
+					t.Fatalf("%s %s %d -> %d: alt %d, dijkstra %d", strategy, m.Name, src, dst, got, want)
+				}
+				if len(path) > 0 && pathCost(t, g, path, m.Cost) != want {
+					t.Fatalf("%s %s %d -> %d: path does not cost %d", strategy, m.Name, src, dst, want)
+				}
+			}
+		}
+	}
+}
+
+func TestALTVirtualEnds(t *testing.T) {
+	g := testGrid(20, 5)
+	edges := g.edges()
+	lm, err := BuildLandmarks(TravelTime, edges, 6, LandmarksAvoid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rnd := rand.New(rand.NewSource(6))
+	for k := range 150 {
+		a, b := edges[rnd.Intn(len(edges))], edges[rnd.Intn(len(edges))]
+		if k%5 == 0 {
+			b = a
+		}
+		vg, err := WithVirtual(g,
+			Virtual{ID: -1, A: a.Src, B: a.Dst, Frac: rnd.Float64()},
+			Virtual{ID: -2, A: b.Dst, B: b.Src, Frac: rnd.Float64()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, q := range [][2]int64{{-1, -2}, {b.Src, -1}, {-2, a.Dst}} {
+			_, want, _, _ := Dijkstra(vg, q[0], q[1], TravelTimeCost)
+			_, got, _, err := ALT(vg, lm, q[0], q[1], TravelTimeCost)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Fatalf("%d -> %d: alt %d, dijkstra %d", q[0], q[1], got, want)
+			}
+		}
+	}
+}
+
+func TestLandmarksSaveLoad(t *testing.T) {
+	g := testGrid(10, 7)
+	lm, err := BuildLandmarks(Distance, g.edges(), 4, LandmarksFarthest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "landmarks.gob")
+	if err := SaveLandmarks(path, lm); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadLandmarks(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Metric != lm.Metric || loaded.Edges != EdgeFingerprint(g.edges(), Distance) || len(loaded.Nodes) != len(lm.Nodes) {
+		t.Fatalf("loaded %s/%x/%d, saved %s/%x/%d", loaded.Metric, loaded.Edges, len(loaded.Nodes), lm.Metric, lm.Edges, len(lm.Nodes))
+	}
+	for _, src := range g.ids[:10] {
+		dst := g.ids[len(g.ids)-1]
+		_, want, _, _ := ALT(g, lm, src, dst, DistanceCost)
+		if _, got, _, _ := ALT(g, loaded, src, dst, DistanceCost); got != want {
+			t.Fatalf("%d -> %d: %d after loading, %d before", src, dst, got, want)
+		}
+	}
+}
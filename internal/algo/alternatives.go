@@ -0,0 +1,135 @@
+package algo
+
+import (
+	"math"
+
+	"github.com/atharv3903/graphion/internal/model"
+)
+
+// AltOptions bounds how alternatives may relate to the primary path.
+type AltOptions struct {
+	// MaxOverlap is the largest share of any already chosen route's cost
+	// an alternative may run along.
+	MaxOverlap float64
+	// MaxStretch is the largest ratio of an alternative's cost to the
+	// primary's.
+	MaxStretch float64
+	// Penalty multiplies the cost of an edge each time a found path uses
+	// it, pushing the next search elsewhere.
+	Penalty float64
+}
+
+var DefaultAltOptions = AltOptions{MaxOverlap: 0.6, MaxStretch: 1.4, Penalty: 1.5}
+
+// Alternative is a route other than the primary, with the share of the
+// primary's cost it also travels.
+type Alternative struct {
+	Result
+	Shared float64
+}
+
+// altAttemptsPerRoute bounds the penalised searches per alternative asked
+// for; graphs without distinct routes would otherwise never stop.
+const altAttemptsPerRoute = 4
+
+// Alternatives finds up to n routes other than primary by the penalty
+// method: after each search the edges of the found path get more expensive
+// and the search is repeated. A found path is kept when it is no more than
+// opt.MaxStretch times the primary's cost and shares at most opt.MaxOverlap
+// of each kept route. Costs reported are the real ones, without penalties.
+func Alternatives(g Graph, primary Result, n int, cost func(int, int) int, opt AltOptions) (alts []Alternative, explored int, err error) {
+	if n <= 0 || len(primary.Path) < 2 {
+		return nil, 0, nil
+	}
+	src, dst := primary.Path[0], primary.Path[len(primary.Path)-1]
+
+	uses := map[[2]int64]int{}
+	penalise := func(path []int64) {
+		for i := 0; i+1 < len(path); i++ {
+			uses[[2]int64{path[i], path[i+1]}]++
+		}
+	}
+	penalised := MapGraph(g, func(e model.Edge) model.Edge {
+		if c := uses[[2]int64{e.Src, e.Dst}]; c > 0 {
+			e.DistM = int(float64(e.DistM) * math.Pow(opt.Penalty, float64(c)))
+		}
+		return e
+	})
+
+	primaryEdges, err := pathEdgeCosts(g, primary.Path, cost)
+	if err != nil {
+		return nil, 0, err
+	}
+	kept := []map[[2]int64]int{primaryEdges}
+	keptTotals := []int{primary.Total}
+	penalise(primary.Path)
+
+	for try := 0; try < n*altAttemptsPerRoute && len(alts) < n; try++ {
+		path, _, searched, err := Dijkstra(penalised, src, dst, cost)
+		explored += searched
+		if err != nil {
+			return nil, explored, err
+		}
+		if len(path) == 0 {
+			break
+		}
+		penalise(path)
+
+		edges, err := pathEdgeCosts(g, path, cost)
+		if err != nil {
+			return nil, explored, err
+		}
+		total := 0
+		for _, c := range edges {
+			total += c
+		}
+		if float64(total) > opt.MaxStretch*float64(primary.Total) {
+			continue
+		}
+
+		ok := true
+		for i, k := range kept {
+			if keptTotals[i] > 0 && float64(sharedCost(edges, k))/float64(keptTotals[i]) > opt.MaxOverlap {
+				ok = false
+				break
+			}
+		}
+		if !ok {
+			continue
+		}
+
+		shared := 0.0
+		if primary.Total > 0 {
+			shared = float64(sharedCost(edges, primaryEdges)) / float64(primary.Total)
+		}
+		alts = append(alts, Alternative{
+			Result: Result{Path: path, Total: total, Explored: searched},
+			Shared: shared,
+		})
+		kept = append(kept, edges)
+		keptTotals = append(keptTotals, total)
+	}
+	return alts, explored, nil
+}
+
+// pathEdgeCosts prices each hop of path under cost.
+func pathEdgeCosts(g Graph, path []int64, cost func(int, int) int) (map[[2]int64]int, error) {
+	prefix, err := prefixCosts(g, path, cost)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[[2]int64]int, len(path))
+	for i := 0; i+1 < len(path); i++ {
+		out[[2]int64{path[i], path[i+1]}] = prefix[i+1] - prefix[i]
+	}
+	return out, nil
+}
+
+// sharedCost is the cost of the hops of a that b uses as well, as priced in b.
+func sharedCost(a, b map[[2]int64]int) int {
+	total := 0
+	for hop := range a {
+		total += b[hop]
+	}
+	return total
+}
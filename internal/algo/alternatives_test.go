@@ -0,0 +1,33 @@
+package algo
+
+import "testing"
+
+func TestAlternatives(t *testing.T) {
+	g := testGrid(25, 3)
+	src, dst := g.ids[0], g.ids[len(g.ids)-1]
+	path, total, explored, err := Dijkstra(g, src, dst, TravelTimeCost)
+	if err != nil || len(path) == 0 {
+		t.Fatal("no primary route", err)
+	}
+	alts, _, err := Alternatives(g, Result{Path: path, Total: total, Explored: explored}, 3, TravelTimeCost, DefaultAltOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(alts) == 0 {
+		t.Fatal("no alternatives on a grid")
+	}
+	for i, a := range alts {
+		if a.Path[0] != src || a.Path[len(a.Path)-1] != dst {
+			t.Fatalf("alternative %d runs %d -> %d", i, a.Path[0], a.Path[len(a.Path)-1])
+		}
+		if pathCost(t, g, a.Path, TravelTimeCost) != a.Total {
+			t.Fatalf("alternative %d: path does not cost %d", i, a.Total)
+		}
+		if a.Total < total || float64(a.Total) > DefaultAltOptions.MaxStretch*float64(total) {
+			t.Fatalf("alternative %d costs %d, primary %d", i, a.Total, total)
+		}
+		if a.Shared > DefaultAltOptions.MaxOverlap {
+			t.Fatalf("alternative %d shares %.2f of the primary", i, a.Shared)
+		}
+	}
+}
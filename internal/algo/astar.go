@@ -0,0 +1,107 @@
+package algo
+
+import "container/heap"
+
+// Heuristic estimates the remaining cost from a node to the search target.
+// It must never overestimate, or A* may return a non-optimal path.
+type Heuristic func(n int64) (int, error)
+
+// ZeroHeuristic turns A* back into plain Dijkstra.
+func ZeroHeuristic(int64) (int, error) { return 0, nil }
+
+// GeoHeuristic lower-bounds the remaining cost to dst under m from the
+// great-circle distance. maxSpeedKmph must be at least the fastest speed in
+// the graph for time-based metrics; 0 disables their speed term. stretch
+// must be at most the ratio of any edge's DistM to the great-circle
+// distance between its ends: the importer floors lengths to whole meters,
+// so a short edge can be stored as much as 1 m shorter than the straight
+// line and no fixed discount covers every graph. 0 disables the heuristic.
+// Nodes without coordinates get 0.
+func GeoHeuristic(g Graph, dst int64, m Metric, maxSpeedKmph int, stretch float64) (Heuristic, error) {
+	if stretch <= 0 {
+		return ZeroHeuristic, nil
+	}
+	target, ok, err := g.Coord(dst)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return ZeroHeuristic, nil
+	}
+
+	return func(n int64) (int, error) {
+		c, ok, err := g.Coord(n)
+		if err != nil || !ok {
+			return 0, err
+		}
+		return m.Bound(Haversine(c, target)*stretch, maxSpeedKmph), nil
+	}, nil
+}
+
+// AStar is Dijkstra ordered by dist+h. With h == ZeroHeuristic it explores
+// exactly like Dijkstra; the tighter h is, the fewer nodes get expanded.
+func AStar(g Graph, src, dst int64, cost func(int, int) int, h Heuristic) ([]int64, int, int, error) {
+	if dg, ok := g.(DenseGraph); ok {
+		return denseAStar(dg, src, dst, cost, h)
+	}
+
+	hsrc, err := h(src)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	dist := map[int64]int{src: 0}
+	hval := map[int64]int{src: hsrc}
+	prev := map[int64]int64{}
+	pq := &pq{}
+	heap.Push(pq, pqItem{node: src, dist: hsrc})
+	explored := 0
+
+	for pq.Len() > 0 {
+		cur := heap.Pop(pq).(pqItem)
+		u := cur.node
+
+		// stale entry, u was re-queued with a better distance since
+		if cur.dist > dist[u]+hval[u] {
+			continue
+		}
+
+		if u == dst {
+			break
+		}
+
+		explored++
+
+		neighbors, err := g.Neighbors(u)
+		if err != nil {
+			return nil, 0, explored, err
+		}
+
+		for _, e := range neighbors {
+			nd := dist[u] + cost(e.DistM, e.Speed)
+
+			old, found := dist[e.Dst]
+			if found && nd >= old {
+				continue
+			}
+
+			hv, seen := hval[e.Dst]
+			if !seen {
+				if hv, err = h(e.Dst); err != nil {
+					return nil, 0, explored, err
+				}
+				hval[e.Dst] = hv
+			}
+
+			dist[e.Dst] = nd
+			prev[e.Dst] = u
+			heap.Push(pq, pqItem{node: e.Dst, dist: nd + hv})
+		}
+	}
+
+	if _, ok := dist[dst]; !ok {
+		return nil, 0, explored, nil
+	}
+
+	return buildPath(prev, src, dst), dist[dst], explored, nil
+}
@@ -0,0 +1,52 @@
+package algo
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/atharv3903/graphion/internal/model"
+)
+
+// TestGeoHeuristicShortEdges checks the heuristic against the true
+// remaining cost along a straight road of 1 and 2 m edges, where every
+// edge's cost is rounded down on its own.
+func TestGeoHeuristicShortEdges(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	g := &testGraph{out: map[int64][]model.Edge{}, in: map[int64][]model.Edge{}, coords: map[int64]model.Coord{}}
+	const n = 400
+	lat := 18.5
+	for i := int64(1); i <= n; i++ {
+		g.ids = append(g.ids, i)
+		g.coords[i] = model.Coord{Lat: lat, Lon: 73.8}
+		// a hair over a whole number of meters north, so the floored
+		// length is barely shorter than the straight line
+		d := 1 + rnd.Intn(2)
+		lat += (float64(d) + 1e-6) / metersPerDegree
+		if i > 1 {
+			e := model.Edge{
+				ID: i, Src: i - 1, Dst: i,
+				DistM: int(Haversine(g.coords[i-1], g.coords[i])),
+				Speed: []int{70, 83, 83, 83, 83, 83, 83, 83}[rnd.Intn(8)],
+			}
+			g.out[e.Src] = append(g.out[e.Src], e)
+			g.in[e.Dst] = append(g.in[e.Dst], e)
+		}
+	}
+	for _, m := range []Metric{TravelTime, Distance, Blend(0.3), Blend(0.9)} {
+		h, err := GeoHeuristic(g, n, m, 83, g.stretch())
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the remaining cost from every node, summed backwards from the end
+		rest := 0
+		for i := int64(n); i >= 1; i-- {
+			if i < n {
+				e := g.out[i][0]
+				rest += m.Cost(e.DistM, e.Speed)
+			}
+			if got, _ := h(i); got > rest {
+				t.Fatalf("%s: h(%d) = %d, but %d remains", m.Name, i, got, rest)
+			}
+		}
+	}
+}
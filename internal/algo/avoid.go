@@ -0,0 +1,153 @@
+package algo
+
+import (
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"sort"
+
+	"github.com/atharv3903/graphion/internal/model"
+)
+
+// Avoid is what one request must not travel on: edges by id, whole road
+// classes, and areas. An edge is in an area when either end node is.
+type Avoid struct {
+	Edges   map[int64]bool
+	Classes map[string]bool
+	// Areas are polygons, as rings of coordinates; the ring may or may
+	// not repeat its first point.
+	Areas [][]model.Coord
+}
+
+// BBox returns the area between two corners as a polygon.
+func BBox(minLat, minLon, maxLat, maxLon float64) []model.Coord {
+	return []model.Coord{
+		{Lat: minLat, Lon: minLon},
+		{Lat: minLat, Lon: maxLon},
+		{Lat: maxLat, Lon: maxLon},
+		{Lat: maxLat, Lon: minLon},
+	}
+}
+
+func (a Avoid) Empty() bool {
+	return len(a.Edges) == 0 && len(a.Classes) == 0 && len(a.Areas) == 0
+}
+
+// Hash identifies the exclusions in route cache keys. Equal exclusions
+// hash equal whatever order they were given in, areas aside.
+func (a Avoid) Hash() uint64 {
+	if a.Empty() {
+		return 0
+	}
+	h := fnv.New64a()
+
+	ids := make([]int64, 0, len(a.Edges))
+	for id := range a.Edges {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	fmt.Fprint(h, "edges", ids)
+
+	classes := make([]string, 0, len(a.Classes))
+	for c := range a.Classes {
+		classes = append(classes, c)
+	}
+	sort.Strings(classes)
+	fmt.Fprint(h, "classes", classes)
+
+	for _, ring := range a.Areas {
+		fmt.Fprint(h, "area", ring)
+	}
+	return h.Sum64()
+}
+
+// avoidGraph hides the edges an Avoid covers. Whether a node lies in an
+// area is worked out once per node.
+type avoidGraph struct {
+	Graph
+	a      Avoid
+	inside map[int64]bool
+}
+
+// AvoidGraph returns g without the edges a covers. The view is meant for
+// one request: it is not safe for concurrent use.
+func AvoidGraph(g Graph, a Avoid) Graph {
+	return &avoidGraph{Graph: g, a: a, inside: map[int64]bool{}}
+}
+
+func (g *avoidGraph) Neighbors(n int64) ([]model.Edge, error) {
+	edges, err := g.Graph.Neighbors(n)
+	if err != nil {
+		return nil, err
+	}
+	return g.filter(edges)
+}
+
+func (g *avoidGraph) InNeighbors(n int64) ([]model.Edge, error) {
+	edges, err := g.Graph.InNeighbors(n)
+	if err != nil {
+		return nil, err
+	}
+	return g.filter(edges)
+}
+
+func (g *avoidGraph) filter(edges []model.Edge) ([]model.Edge, error) {
+	var out []model.Edge
+	for _, e := range edges {
+		if g.a.Edges[e.ID] || g.a.Classes[e.Highway] {
+			continue
+		}
+		in, err := g.inArea(e.Src)
+		if err != nil {
+			return nil, err
+		}
+		if !in {
+			in, err = g.inArea(e.Dst)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if !in {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func (g *avoidGraph) inArea(n int64) (bool, error) {
+	if len(g.a.Areas) == 0 {
+		return false, nil
+	}
+	if in, ok := g.inside[n]; ok {
+		return in, nil
+	}
+	c, ok, err := g.Coord(n)
+	if err != nil {
+		return false, err
+	}
+	in := false
+	if ok {
+		for _, ring := range g.a.Areas {
+			if inPolygon(ring, c) {
+				in = true
+				break
+			}
+		}
+	}
+	g.inside[n] = in
+	return in, nil
+}
+
+// inPolygon is the even-odd rule on raw lat/lon, which is fine at city
+// scale away from the antimeridian.
+func inPolygon(ring []model.Coord, c model.Coord) bool {
+	in := false
+	for i, v := range ring {
+		w := ring[(i+1)%len(ring)]
+		if (v.Lat > c.Lat) != (w.Lat > c.Lat) &&
+			c.Lon < v.Lon+(c.Lat-v.Lat)*(w.Lon-v.Lon)/(w.Lat-v.Lat) {
+			in = !in
+		}
+	}
+	return in
+}
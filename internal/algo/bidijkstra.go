@@ -0,0 +1,116 @@
+package algo
+
+import (
+	"container/heap"
+
+	"github.com/atharv3903/graphion/internal/model"
+)
+
+// search is one direction of a bidirectional search.
+type search struct {
+	dist    map[int64]int
+	prev    map[int64]int64 // predecessor towards the side's own root
+	pq      *pq
+	expand  func(int64) ([]model.Edge, error)
+	forward bool
+}
+
+func newSearch(root int64, forward bool, expand func(int64) ([]model.Edge, error)) *search {
+	s := &search{
+		dist:    map[int64]int{root: 0},
+		prev:    map[int64]int64{},
+		pq:      &pq{},
+		expand:  expand,
+		forward: forward,
+	}
+	heap.Push(s.pq, pqItem{node: root, dist: 0})
+	return s
+}
+
+// top drops stale queue entries and returns the smallest live key.
+func (s *search) top() (int, bool) {
+	for s.pq.Len() > 0 {
+		it := (*s.pq)[0]
+		if it.dist <= s.dist[it.node] {
+			return it.dist, true
+		}
+		heap.Pop(s.pq)
+	}
+	return 0, false
+}
+
+// BidirectionalDijkstra grows one Dijkstra ball from src over outgoing edges
+// and one from dst over incoming edges, always advancing the side with the
+// smaller queue head. It stops once the two heads together can no longer
+// beat the best src-dst connection seen so far, which is exactly the
+// optimum. Closed edges are skipped by both Outgoing and Incoming, so they
+// are respected in both directions.
+func BidirectionalDijkstra(g Graph, src, dst int64, cost func(int, int) int) ([]int64, int, int, error) {
+	if src == dst {
+		return []int64{src}, 0, 0, nil
+	}
+
+	fwd := newSearch(src, true, g.Neighbors)
+	bwd := newSearch(dst, false, g.InNeighbors)
+
+	best, meet, found := 0, int64(0), false
+	explored := 0
+
+	for {
+		ftop, fok := fwd.top()
+		btop, bok := bwd.top()
+		// an exhausted side has settled everything it can reach, and every
+		// connection through it was already recorded while relaxing
+		if !fok || !bok {
+			break
+		}
+		if found && ftop+btop >= best {
+			break
+		}
+
+		cur, other := fwd, bwd
+		if btop < ftop {
+			cur, other = bwd, fwd
+		}
+
+		u := heap.Pop(cur.pq).(pqItem).node
+		explored++
+
+		edges, err := cur.expand(u)
+		if err != nil {
+			return nil, 0, explored, err
+		}
+
+		for _, e := range edges {
+			v := e.Dst
+			if !cur.forward {
+				v = e.Src
+			}
+
+			nd := cur.dist[u] + cost(e.DistM, e.Speed)
+			if old, ok := cur.dist[v]; ok && nd >= old {
+				continue
+			}
+			cur.dist[v] = nd
+			cur.prev[v] = u
+			heap.Push(cur.pq, pqItem{node: v, dist: nd})
+
+			if od, ok := other.dist[v]; ok && (!found || nd+od < best) {
+				best, meet, found = nd+od, v, true
+			}
+		}
+	}
+
+	if !found {
+		return nil, 0, explored, nil
+	}
+
+	// src..meet from the forward tree, then meet..dst from the backward one
+	path := buildPath(fwd.prev, src, meet)
+	for cur := meet; cur != dst; {
+		cur = bwd.prev[cur]
+		path = append(path, cur)
+	}
+
+	return path, best, explored, nil
+}
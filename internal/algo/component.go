@@ -0,0 +1,121 @@
+package algo
+
+import "github.com/atharv3903/graphion/internal/model"
+
+// LargestComponent returns the nodes of the largest strongly connected
+// component of the graph formed by edges: the biggest set of nodes that
+// can all reach each other. Nodes outside it are islands such as car parks
+// or private roads a route may get into but not out of, or the reverse.
+func LargestComponent(edges []model.Edge) map[int64]bool {
+	index := map[int64]int32{}
+	var ids []int64
+	node := func(id int64) int32 {
+		if v, ok := index[id]; ok {
+			return v
+		}
+		v := int32(len(ids))
+		index[id] = v
+		ids = append(ids, id)
+		return v
+	}
+	tails := make([]int32, len(edges))
+	heads := make([]int32, len(edges))
+	for i, e := range edges {
+		tails[i], heads[i] = node(e.Src), node(e.Dst)
+	}
+	n := len(ids)
+	fwdOff, fwd := adjacency(n, tails, heads)
+	revOff, rev := adjacency(n, heads, tails)
+
+	// Kosaraju: finish order on the graph, then components on the reverse
+	// graph in reverse finish order. Both walks keep explicit stacks, as a
+	// road network is far too deep to recurse through.
+	seen := make([]bool, n)
+	order := make([]int32, 0, n)
+	type frame struct{ v, next int32 }
+	var stack []frame
+	for s := range n {
+		if seen[s] {
+			continue
+		}
+		seen[s] = true
+		stack = append(stack, frame{int32(s), fwdOff[s]})
+		for len(stack) > 0 {
+			top := &stack[len(stack)-1]
+			if top.next < fwdOff[top.v+1] {
+				w := fwd[top.next]
+				top.next++
+				if !seen[w] {
+					seen[w] = true
+					stack = append(stack, frame{w, fwdOff[w]})
+				}
+				continue
+			}
+			order = append(order, top.v)
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	comp := make([]int32, n)
+	for v := range comp {
+		comp[v] = -1
+	}
+	var sizes []int
+	var todo []int32
+	for k := n - 1; k >= 0; k-- {
+		v := order[k]
+		if comp[v] >= 0 {
+			continue
+		}
+		c := int32(len(sizes))
+		size := 0
+		comp[v] = c
+		todo = append(todo[:0], v)
+		for len(todo) > 0 {
+			u := todo[len(todo)-1]
+			todo = todo[:len(todo)-1]
+			size++
+			for _, w := range rev[revOff[u]:revOff[u+1]] {
+				if comp[w] < 0 {
+					comp[w] = c
+					todo = append(todo, w)
+				}
+			}
+		}
+		sizes = append(sizes, size)
+	}
+
+	largest := int32(0)
+	for c, size := range sizes {
+		if size > sizes[largest] {
+			largest = int32(c)
+		}
+	}
+	out := map[int64]bool{}
+	for v, c := range comp {
+		if c == largest {
+			out[ids[v]] = true
+		}
+	}
+	return out
+}
+
+// adjacency groups the ends of edges by their starts, as row offsets and
+// the grouped ends.
+func adjacency(n int, from, to []int32) ([]int32, []int32) {
+	off := make([]int32, n+1)
+	for _, f := range from {
+		off[f+1]++
+	}
+	for v := 0; v < n; v++ {
+		off[v+1] += off[v]
+	}
+	next := make([]int32, n)
+	copy(next, off[:n])
+	out := make([]int32, len(from))
+	for i, f := range from {
+		out[next[f]] = to[i]
+		next[f]++
+	}
+	return off, out
+}
@@ -0,0 +1,61 @@
+package algo
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/atharv3903/graphion/internal/model"
+)
+
+func TestLargestComponentMatchesReachability(t *testing.T) {
+	rnd := rand.New(rand.NewSource(3))
+	for range 50 {
+		var edges []model.Edge
+		adj := map[int64][]int64{}
+		for range 45 {
+			a, b := int64(rnd.Intn(30)), int64(rnd.Intn(30))
+			edges = append(edges, model.Edge{Src: a, Dst: b})
+			adj[a] = append(adj[a], b)
+		}
+		reach := func(s int64) map[int64]bool {
+			seen := map[int64]bool{s: true}
+			stack := []int64{s}
+			for len(stack) > 0 {
+				u := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				for _, w := range adj[u] {
+					if !seen[w] {
+						seen[w] = true
+						stack = append(stack, w)
+					}
+				}
+			}
+			return seen
+		}
+
+		// the largest set of nodes that reach each other, the slow way
+		want := 0
+		for _, e := range edges {
+			size := 0
+			for w := range reach(e.Src) {
+				if reach(w)[e.Src] {
+					size++
+				}
+			}
+			want = max(want, size)
+		}
+
+		got := LargestComponent(edges)
+		if len(got) != want {
+			t.Fatalf("component of %d nodes, want %d", len(got), want)
+		}
+		for a := range got {
+			r := reach(a)
+			for b := range got {
+				if !r[b] {
+					t.Fatalf("%d does not reach %d", a, b)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package algo
+
+import (
+	"math"
+
+	"github.com/atharv3903/graphion/internal/model"
+)
+
+// metersPerDegree is the length of one degree of latitude.
+const metersPerDegree = earthRadiusM * math.Pi / 180
+
+type cell [2]int
+
+// GridContour outlines points on a grid of cellM meter squares: every cell
+// holding a point is filled and the outline of the filled cells is traced.
+// The result is a list of polygons, each an outer ring (counterclockwise)
+// followed by its holes (clockwise), every ring closed.
+func GridContour(points []model.Coord, cellM float64) [][][]model.Coord {
+	if len(points) == 0 || cellM <= 0 {
+		return nil
+	}
+
+	// equirectangular projection around the first point, fine over the
+	// tens of kilometers an isochrone spans
+	origin := points[0]
+	kx := math.Cos(origin.Lat*math.Pi/180) * metersPerDegree / cellM
+	ky := metersPerDegree / cellM
+
+	filled := map[cell]bool{}
+	for _, p := range points {
+		x := math.Floor((p.Lon - origin.Lon) * kx)
+		y := math.Floor((p.Lat - origin.Lat) * ky)
+		filled[cell{int(x), int(y)}] = true
+	}
+
+	var outers, holes [][]cell
+	for _, ring := range traceRings(filled) {
+		if ringArea(ring) > 0 {
+			outers = append(outers, ring)
+		} else {
+			holes = append(holes, ring)
+		}
+	}
+
+	polys := make([][][]cell, len(outers))
+	for i, o := range outers {
+		polys[i] = [][]cell{o}
+	}
+	for _, h := range holes {
+		// a point just inside the filled cell on the left of the hole's
+		// first unit step; off the grid lines, so the ray test is
+		// unambiguous. Simplified edges span many cells, so only their
+		// direction is used.
+		dx, dy := sign(h[1][0]-h[0][0]), sign(h[1][1]-h[0][1])
+		px := float64(h[0][0]) + 0.5*float64(dx) - 0.25*float64(dy)
+		py := float64(h[0][1]) + 0.5*float64(dy) + 0.25*float64(dx)
+
+		best := -1
+		for i, o := range outers {
+			if insideRing(o, px, py) && (best < 0 || ringArea(o) < ringArea(outers[best])) {
+				best = i
+			}
+		}
+		if best >= 0 {
+			polys[best] = append(polys[best], h)
+		}
+	}
+
+	out := make([][][]model.Coord, len(polys))
+	for i, rings := range polys {
+		for _, ring := range rings {
+			coords := make([]model.Coord, 0, len(ring)+1)
+			for _, v := range ring {
+				coords = append(coords, model.Coord{
+					Lat: origin.Lat + float64(v[1])/ky,
+					Lon: origin.Lon + float64(v[0])/kx,
+				})
+			}
+			coords = append(coords, coords[0])
+			out[i] = append(out[i], coords)
+		}
+	}
+	return out
+}
+
+func sign(v int) int {
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	}
+	return 0
+}
+
+// traceRings returns the boundary of the filled cells as rings of grid
+// vertices, each with the filled side on its left. Where two filled cells
+// touch only at a corner the rings are kept apart.
+func traceRings(filled map[cell]bool) [][]cell {
+	next := map[cell][]cell{}
+	add := func(a, b cell) { next[a] = append(next[a], b) }
+	for c := range filled {
+		x, y := c[0], c[1]
+		if !filled[cell{x, y - 1}] {
+			add(cell{x, y}, cell{x + 1, y})
+		}
+		if !filled[cell{x + 1, y}] {
+			add(cell{x + 1, y}, cell{x + 1, y + 1})
+		}
+		if !filled[cell{x, y + 1}] {
+			add(cell{x + 1, y + 1}, cell{x, y + 1})
+		}
+		if !filled[cell{x - 1, y}] {
+			add(cell{x, y + 1}, cell{x, y})
+		}
+	}
+
+	var rings [][]cell
+	for start, outs := range next {
+		for len(outs) > 0 {
+			ring := []cell{start}
+			prev, cur := start, outs[0]
+			next[start] = outs[1:]
+			for cur != start {
+				ring = append(ring, cur)
+				prev, cur = cur, takeLeftmost(next, prev, cur)
+			}
+			rings = append(rings, simplifyRing(ring))
+			outs = next[start]
+		}
+	}
+	return rings
+}
+
+// takeLeftmost removes and returns the edge out of cur that turns most to
+// the left coming from prev.
+func takeLeftmost(next map[cell][]cell, prev, cur cell) cell {
+	outs := next[cur]
+	dx, dy := cur[0]-prev[0], cur[1]-prev[1]
+	best := 0
+	for i := 1; i < len(outs); i++ {
+		if turn(dx, dy, outs[i], cur) > turn(dx, dy, outs[best], cur) {
+			best = i
+		}
+	}
+	v := outs[best]
+	next[cur] = append(outs[:best:best], outs[best+1:]...)
+	return v
+}
+
+// turn ranks the direction cur->to against (dx, dy): left 1, straight 0,
+// right -1.
+func turn(dx, dy int, to, cur cell) int {
+	return dx*(to[1]-cur[1]) - dy*(to[0]-cur[0])
+}
+
+// simplifyRing drops the vertices in the middle of straight runs.
+func simplifyRing(ring []cell) []cell {
+	n := len(ring)
+	out := make([]cell, 0, n)
+	for i, v := range ring {
+		a, b := ring[(i+n-1)%n], ring[(i+1)%n]
+		if (v[0]-a[0])*(b[1]-v[1])-(v[1]-a[1])*(b[0]-v[0]) != 0 {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// ringArea is the signed area of ring, positive when counterclockwise.
+func ringArea(ring []cell) int {
+	a := 0
+	for i, v := range ring {
+		w := ring[(i+1)%len(ring)]
+		a += v[0]*w[1] - w[0]*v[1]
+	}
+	return a / 2
+}
+
+func insideRing(ring []cell, px, py float64) bool {
+	in := false
+	for i, v := range ring {
+		w := ring[(i+1)%len(ring)]
+		x1, y1, x2, y2 := float64(v[0]), float64(v[1]), float64(w[0]), float64(w[1])
+		if (y1 > py) != (y2 > py) && px < x1+(py-y1)*(x2-x1)/(y2-y1) {
+			in = !in
+		}
+	}
+	return in
+}
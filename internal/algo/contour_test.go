@@ -0,0 +1,42 @@
+package algo
+
+import (
+	"math"
+	"testing"
+
+	"github.com/atharv3903/graphion/internal/model"
+)
+
+func TestGridContourHoles(t *testing.T) {
+	const cellM = 100
+	kx := math.Cos(18.5*math.Pi/180) * metersPerDegree / cellM
+	ky := metersPerDegree / cellM
+	for _, hole := range []int{1, 3, 4, 5, 8, 12} {
+		// a ring of cells one wide around a square hole, with a point on
+		// the origin's corner to pin the grid
+		n := hole + 2
+		pts := []model.Coord{{Lat: 18.5, Lon: 73.8}}
+		for x := range n {
+			for y := range n {
+				if x >= 1 && x <= hole && y >= 1 && y <= hole {
+					continue
+				}
+				pts = append(pts, model.Coord{Lat: 18.5 + (float64(y)+0.5)/ky, Lon: 73.8 + (float64(x)+0.5)/kx})
+			}
+		}
+		polys := GridContour(pts, cellM)
+		if len(polys) != 1 || len(polys[0]) != 2 {
+			t.Fatalf("hole of %d cells: %d polygons, want 1 with an outer ring and a hole", hole, len(polys))
+		}
+	}
+}
+
+func TestGridContourSeparateAreas(t *testing.T) {
+	pts := []model.Coord{
+		{Lat: 18.5, Lon: 73.8},
+		{Lat: 18.5, Lon: 73.9},
+	}
+	if polys := GridContour(pts, 100); len(polys) != 2 {
+		t.Fatalf("%d polygons, want 2", len(polys))
+	}
+}
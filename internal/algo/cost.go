@@ -0,0 +1,137 @@
+package algo
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DistanceCost charges an edge its length in meters.
+func DistanceCost(distM, speed int) int {
+	return distM
+}
+
+// TravelTimeCost charges an edge its traversal time in milliseconds. Edges
+// with no usable speed are treated as crawling at 1 km/h rather than free.
+func TravelTimeCost(distM, speed int) int {
+	if speed <= 0 {
+		speed = 1
+	}
+	return distM * 3600 / speed
+}
+
+// Metric is a named way of pricing edges. Costs are integers in the
+// metric's own unit (meters for distance, milliseconds for time).
+type Metric struct {
+	Name string
+	Cost func(distM, speed int) int
+	// Bound turns a straight-line distance in meters into a lower bound on
+	// the cost of covering it, given the fastest speed in the graph. A*
+	// builds its heuristic from it.
+	Bound func(meters float64, maxSpeedKmph int) int
+	// UsesSpeed is false when speed updates cannot change costs, which lets
+	// preprocessed data survive them.
+	UsesSpeed bool
+}
+
+var (
+	Distance = Metric{
+		Name: "distance",
+		Cost: DistanceCost,
+		Bound: func(m float64, _ int) int {
+			return int(m)
+		},
+	}
+	TravelTime = Metric{
+		Name:      "time",
+		Cost:      TravelTimeCost,
+		Bound:     timeBound,
+		UsesSpeed: true,
+	}
+)
+
+// timeBound lower-bounds the travel time in ms over m meters of edges no
+// faster than maxSpeedKmph, or is 0 when the top speed is unknown.
+// TravelTimeCost rounds each edge down by up to maxSpeedKmph-1 units of
+// 1/maxSpeedKmph ms, and a path of many short edges loses that on every
+// one; as a costed edge is at least 1 m long, the loss is taken off per
+// meter.
+func timeBound(m float64, maxSpeedKmph int) int {
+	if maxSpeedKmph <= 0 {
+		return 0
+	}
+	return max(0, int(m*float64(3601-maxSpeedKmph)/float64(maxSpeedKmph)))
+}
+
+// blendRefSpeed converts meters into milliseconds for the blended metric,
+// so that its two halves are in the same unit.
+const blendRefSpeed = 50
+
+// Blend mixes travel time and distance: timeWeight of the edge's travel time
+// plus the rest of the time it would take at blendRefSpeed. timeWeight 1 is
+// TravelTime, 0 ranks like Distance.
+func Blend(timeWeight float64) Metric {
+	// the name keys route caches, so two weights with the same name must
+	// also price edges the same
+	w := math.Round(timeWeight*100) / 100
+	return Metric{
+		Name: fmt.Sprintf("blend:%.2f", w),
+		Cost: func(distM, speed int) int {
+			return int(w*float64(TravelTimeCost(distM, speed)) + (1-w)*float64(distM*3600/blendRefSpeed))
+		},
+		// Cost rounds each edge down by less than 1 again, which is taken
+		// off per meter like in timeBound
+		Bound: func(m float64, maxSpeedKmph int) int {
+			return max(0, int(w*float64(timeBound(m, maxSpeedKmph))+(1-w)*m*3600/blendRefSpeed-m))
+		},
+		UsesSpeed: w > 0,
+	}
+}
+
+var metrics = map[string]Metric{}
+
+// RegisterMetric makes m selectable by name through ParseMetric.
+func RegisterMetric(m Metric) {
+	metrics[m.Name] = m
+}
+
+func init() {
+	RegisterMetric(Distance)
+	RegisterMetric(TravelTime)
+}
+
+// ParseMetric resolves a metric name as given on /route. Empty means
+// distance; "blend" and "blend:<w>" build a Blend with time weight w
+// (0.5 by default).
+func ParseMetric(name string) (Metric, error) {
+	if name == "" {
+		return Distance, nil
+	}
+	if m, ok := metrics[name]; ok {
+		return m, nil
+	}
+	if name == "blend" {
+		return Blend(0.5), nil
+	}
+	if ws, ok := strings.CutPrefix(name, "blend:"); ok {
+		w, err := strconv.ParseFloat(ws, 64)
+		// the negated range also turns away NaN
+		if err != nil || !(w >= 0 && w <= 1) {
+			return Metric{}, fmt.Errorf("blend weight must be in [0,1], got %q", ws)
+		}
+		return Blend(w), nil
+	}
+	return Metric{}, fmt.Errorf("unknown metric %q", name)
+}
+
+// MetricNames lists the registered metrics.
+func MetricNames() []string {
+	names := make([]string, 0, len(metrics))
+	for n := range metrics {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,129 @@
+package algo
+
+import (
+	"container/heap"
+	"sync"
+)
+
+// denseState is the scratch space of one search over a DenseGraph. An entry
+// is only valid where seen[v] == gen, so handing the state to the next
+// search is a counter bump rather than clearing every slice.
+type denseState struct {
+	dist []int
+	hval []int
+	prev []uint32
+	seen []uint32
+	gen  uint32
+}
+
+var densePool sync.Pool
+
+func acquireDense(n int) *denseState {
+	st, ok := densePool.Get().(*denseState)
+	if !ok || len(st.dist) != n {
+		return &denseState{
+			dist: make([]int, n),
+			hval: make([]int, n),
+			prev: make([]uint32, n),
+			seen: make([]uint32, n),
+			gen:  1,
+		}
+	}
+	st.gen++
+	if st.gen == 0 {
+		clear(st.seen)
+		st.gen = 1
+	}
+	return st
+}
+
+// denseAStar is AStar over slice-indexed state. A nil h makes it Dijkstra.
+func denseAStar(g DenseGraph, src, dst int64, cost func(int, int) int, h Heuristic) ([]int64, int, int, error) {
+	if src == dst {
+		return []int64{src}, 0, 0, nil
+	}
+	s, ok := g.Index(src)
+	if !ok {
+		return nil, 0, 0, nil
+	}
+	t, ok := g.Index(dst)
+	if !ok {
+		return nil, 0, 0, nil
+	}
+
+	st := acquireDense(g.Len())
+	defer densePool.Put(st)
+
+	estimate := func(v uint32) (int, error) {
+		if h == nil {
+			return 0, nil
+		}
+		return h(g.ID(v))
+	}
+
+	hs, err := estimate(s)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	st.seen[s], st.dist[s], st.hval[s] = st.gen, 0, hs
+
+	lim, _ := g.(*limitedDense)
+
+	pq := &pq{}
+	heap.Push(pq, pqItem{node: int64(s), dist: hs})
+	explored := 0
+
+	for pq.Len() > 0 {
+		cur := heap.Pop(pq).(pqItem)
+		u := uint32(cur.node)
+
+		if cur.dist > st.dist[u]+st.hval[u] {
+			continue
+		}
+		if u == t {
+			break
+		}
+		explored++
+		if lim != nil {
+			if err := lim.l.expand(); err != nil {
+				return nil, 0, explored, err
+			}
+		}
+
+		edges, heads := g.Out(u)
+		for i, e := range edges {
+			v := heads[i]
+			nd := st.dist[u] + cost(e.DistM, e.Speed)
+
+			if st.seen[v] == st.gen {
+				if nd >= st.dist[v] {
+					continue
+				}
+			} else {
+				if st.hval[v], err = estimate(v); err != nil {
+					return nil, 0, explored, err
+				}
+				st.seen[v] = st.gen
+			}
+
+			st.dist[v] = nd
+			st.prev[v] = u
+			heap.Push(pq, pqItem{node: int64(v), dist: nd + st.hval[v]})
+		}
+	}
+
+	if st.seen[t] != st.gen {
+		return nil, 0, explored, nil
+	}
+
+	path := []int64{}
+	for v := t; v != s; v = st.prev[v] {
+		path = append(path, g.ID(v))
+	}
+	path = append(path, src)
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+
+	return path, st.dist[t], explored, nil
+}
@@ -25,7 +25,11 @@ func (p *pq) Pop() any {
 	return item
 }
 
-func Dijkstra(ctx GraphCtx, src, dst int64, cost func(int, int) int) ([]int64, int, int, error) {
+func Dijkstra(g Graph, src, dst int64, cost func(int, int) int) ([]int64, int, int, error) {
+	if dg, ok := g.(DenseGraph); ok {
+		return denseAStar(dg, src, dst, cost, nil)
+	}
+
 	dist := map[int64]int{src: 0}
 	prev := map[int64]int64{}
 	pq := &pq{}
@@ -42,7 +46,7 @@ func Dijkstra(ctx GraphCtx, src, dst int64, cost func(int, int) int) ([]int64, i
 
 		explored++
 
-		neighbors, err := ctx.Neighbors(u)
+		neighbors, err := g.Neighbors(u)
 		if err != nil {
 			return nil, 0, explored, err
 		}
@@ -65,7 +69,12 @@ func Dijkstra(ctx GraphCtx, src, dst int64, cost func(int, int) int) ([]int64, i
 		return nil, 0, explored, nil
 	}
 
-	// reconstruct
+	return buildPath(prev, src, dst), dist[dst], explored, nil
+}
+
+// buildPath walks prev back from dst to src and returns the path in
+// travel order.
+func buildPath(prev map[int64]int64, src, dst int64) []int64 {
 	path := []int64{}
 	cur := dst
 
@@ -79,5 +88,5 @@ func Dijkstra(ctx GraphCtx, src, dst int64, cost func(int, int) int) ([]int64, i
 		path[i], path[j] = path[j], path[i]
 	}
 
-	return path, dist[dst], explored, nil
+	return path
 }
@@ -0,0 +1,65 @@
+package algo
+
+import "github.com/atharv3903/graphion/internal/model"
+
+// filteredGraph hides every edge keep rejects, in both directions.
+type filteredGraph struct {
+	Graph
+	keep func(model.Edge) bool
+}
+
+// FilterGraph returns a view of g without the edges keep rejects. The view
+// is not a DenseGraph, so searches over it use map-based state.
+func FilterGraph(g Graph, keep func(model.Edge) bool) Graph {
+	return filteredGraph{Graph: g, keep: keep}
+}
+
+func (f filteredGraph) Neighbors(n int64) ([]model.Edge, error) {
+	edges, err := f.Graph.Neighbors(n)
+	return f.filter(edges), err
+}
+
+func (f filteredGraph) InNeighbors(n int64) ([]model.Edge, error) {
+	edges, err := f.Graph.InNeighbors(n)
+	return f.filter(edges), err
+}
+
+func (f filteredGraph) filter(edges []model.Edge) []model.Edge {
+	var out []model.Edge
+	for _, e := range edges {
+		if f.keep(e) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// mappedGraph rewrites edges on the way out of an underlying Graph.
+type mappedGraph struct {
+	Graph
+	fn func(model.Edge) model.Edge
+}
+
+// MapGraph returns a view of g with fn applied to every edge, e.g. to
+// scale lengths. Like FilterGraph the view is not a DenseGraph.
+func MapGraph(g Graph, fn func(model.Edge) model.Edge) Graph {
+	return mappedGraph{Graph: g, fn: fn}
+}
+
+func (m mappedGraph) Neighbors(n int64) ([]model.Edge, error) {
+	edges, err := m.Graph.Neighbors(n)
+	return m.mapEdges(edges), err
+}
+
+func (m mappedGraph) InNeighbors(n int64) ([]model.Edge, error) {
+	edges, err := m.Graph.InNeighbors(n)
+	return m.mapEdges(edges), err
+}
+
+func (m mappedGraph) mapEdges(edges []model.Edge) []model.Edge {
+	out := make([]model.Edge, len(edges))
+	for i, e := range edges {
+		out[i] = m.fn(e)
+	}
+	return out
+}
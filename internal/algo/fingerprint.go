@@ -0,0 +1,32 @@
+package algo
+
+import "github.com/atharv3903/graphion/internal/model"
+
+// EdgeFingerprint summarises edges so that data preprocessed for m can tell
+// whether it was built from the same roads: it changes when an edge is
+// added or removed or changes its ends or length, or its speed if m uses
+// speeds, whatever order the edges come in.
+func EdgeFingerprint(edges []model.Edge, m Metric) uint64 {
+	fp := uint64(len(edges))
+	for _, e := range edges {
+		h := uint64(e.ID)
+		for _, x := range []uint64{uint64(e.Src), uint64(e.Dst), uint64(e.DistM)} {
+			h = mix64(h ^ x)
+		}
+		if m.UsesSpeed {
+			h = mix64(h ^ uint64(e.Speed))
+		}
+		fp += mix64(h)
+	}
+	return fp
+}
+
+// mix64 is the splitmix64 finalizer.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
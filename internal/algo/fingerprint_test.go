@@ -0,0 +1,20 @@
+package algo
+
+import "testing"
+
+func TestEdgeFingerprintSpeed(t *testing.T) {
+	edges := testGrid(5, 1).edges()
+	faster := append(edges[:0:0], edges...)
+	faster[3].Speed += 10
+	if EdgeFingerprint(edges, Distance) != EdgeFingerprint(faster, Distance) {
+		t.Fatal("distance fingerprint changed with a speed")
+	}
+	if EdgeFingerprint(edges, TravelTime) == EdgeFingerprint(faster, TravelTime) {
+		t.Fatal("time fingerprint did not change with a speed")
+	}
+	longer := append(edges[:0:0], edges...)
+	longer[3].DistM++
+	if EdgeFingerprint(edges, Distance) == EdgeFingerprint(longer, Distance) {
+		t.Fatal("distance fingerprint did not change with a length")
+	}
+}
@@ -0,0 +1,47 @@
+package algo
+
+import (
+	"math"
+
+	"github.com/atharv3903/graphion/internal/model"
+)
+
+// same radius as tools/import_osm.py, so edge lengths and heuristics agree
+const earthRadiusM = 6371000
+
+// Haversine returns the great-circle distance between a and b in meters.
+func Haversine(a, b model.Coord) float64 {
+	phi1 := a.Lat * math.Pi / 180
+	phi2 := b.Lat * math.Pi / 180
+	dphi := (b.Lat - a.Lat) * math.Pi / 180
+	dlambda := (b.Lon - a.Lon) * math.Pi / 180
+
+	h := math.Sin(dphi/2)*math.Sin(dphi/2) +
+		math.Cos(phi1)*math.Cos(phi2)*math.Sin(dlambda/2)*math.Sin(dlambda/2)
+	return earthRadiusM * 2 * math.Atan2(math.Sqrt(h), math.Sqrt(1-h))
+}
+
+// MinStretch is the smallest ratio of an edge's length to the Haversine
+// distance between its ends, at most 1 and 1 for no edges. Edges whose ends
+// coincide or lack coordinates don't count. It bounds how far stored
+// lengths fall below straight-line distances, for GeoHeuristic; the result
+// is shaded down a hair so that float rounding in the heuristic cannot
+// carry it past an edge's length.
+func MinStretch(nodes []model.Node, edges []model.Edge) float64 {
+	coords := make(map[int64]model.Coord, len(nodes))
+	for _, n := range nodes {
+		coords[n.ID] = n.Coord
+	}
+	v := 1.0
+	for _, e := range edges {
+		a, aok := coords[e.Src]
+		b, bok := coords[e.Dst]
+		if !aok || !bok {
+			continue
+		}
+		if d := Haversine(a, b); d > 0 {
+			v = min(v, float64(e.DistM)/d)
+		}
+	}
+	return v * (1 - 1e-9)
+}
@@ -0,0 +1,30 @@
+package algo
+
+import "github.com/atharv3903/graphion/internal/model"
+
+// Graph is the neighbour source every search runs against. GraphCtx serves
+// it from MySQL through the adjacency caches; graph.CSR serves it from
+// memory.
+type Graph interface {
+	// Neighbors returns the open edges leaving n. Callers must not modify
+	// the returned slice.
+	Neighbors(n int64) ([]model.Edge, error)
+	// InNeighbors returns the open edges ending at n, in their original
+	// orientation.
+	InNeighbors(n int64) ([]model.Edge, error)
+	// Coord returns the position of n; ok is false when it is unknown.
+	Coord(n int64) (model.Coord, bool, error)
+}
+
+// DenseGraph is a Graph whose nodes are also numbered 0..Len()-1. Searches
+// that see one keep their state in slices indexed by node number instead of
+// maps keyed by node id.
+type DenseGraph interface {
+	Graph
+	Len() int
+	Index(id int64) (uint32, bool)
+	ID(v uint32) int64
+	// Out returns the edges leaving v together with the dense number of
+	// each edge's head.
+	Out(v uint32) ([]model.Edge, []uint32)
+}
@@ -0,0 +1,138 @@
+package algo
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/atharv3903/graphion/internal/model"
+)
+
+// testGraph is an in-memory Graph for the tests, without the dense fast
+// paths so that the searches take their general code.
+type testGraph struct {
+	out, in map[int64][]model.Edge
+	coords  map[int64]model.Coord
+	ids     []int64
+}
+
+func (g *testGraph) Neighbors(n int64) ([]model.Edge, error)   { return g.out[n], nil }
+func (g *testGraph) InNeighbors(n int64) ([]model.Edge, error) { return g.in[n], nil }
+
+func (g *testGraph) Coord(n int64) (model.Coord, bool, error) {
+	c, ok := g.coords[n]
+	return c, ok, nil
+}
+
+// edges returns every edge of g.
+func (g *testGraph) edges() []model.Edge {
+	var out []model.Edge
+	for _, n := range g.ids {
+		out = append(out, g.out[n]...)
+	}
+	return out
+}
+
+// stretch is the MinStretch of g.
+func (g *testGraph) stretch() float64 {
+	var nodes []model.Node
+	for _, n := range g.ids {
+		nodes = append(nodes, model.Node{ID: n, Coord: g.coords[n]})
+	}
+	return MinStretch(nodes, g.edges())
+}
+
+// testGrid builds an n by n street grid of about 55 m blocks with jittered
+// corners, random speeds and one road direction in ten missing. Lengths
+// are floored to whole meters the way the importer stores them.
+func testGrid(n int, seed int64) *testGraph {
+	rnd := rand.New(rand.NewSource(seed))
+	g := &testGraph{out: map[int64][]model.Edge{}, in: map[int64][]model.Edge{}, coords: map[int64]model.Coord{}}
+	id := func(i, j int) int64 { return int64(i*n + j + 1) }
+	for i := range n {
+		for j := range n {
+			g.ids = append(g.ids, id(i, j))
+			g.coords[id(i, j)] = model.Coord{
+				Lat: 18.5 + float64(i)*0.0005 + rnd.Float64()*0.0002,
+				Lon: 73.8 + float64(j)*0.0005 + rnd.Float64()*0.0002,
+			}
+		}
+	}
+	eid := int64(0)
+	add := func(a, b int64) {
+		if rnd.Intn(10) == 0 {
+			return
+		}
+		eid++
+		e := model.Edge{
+			ID: eid, Src: a, Dst: b,
+			DistM: int(Haversine(g.coords[a], g.coords[b])),
+			Speed: []int{30, 40, 60, 90}[rnd.Intn(4)],
+		}
+		g.out[a] = append(g.out[a], e)
+		g.in[b] = append(g.in[b], e)
+	}
+	for i := range n {
+		for j := range n {
+			if i+1 < n {
+				add(id(i, j), id(i+1, j))
+				add(id(i+1, j), id(i, j))
+			}
+			if j+1 < n {
+				add(id(i, j), id(i, j+1))
+				add(id(i, j+1), id(i, j))
+			}
+		}
+	}
+	return g
+}
+
+// pathCost sums the cheapest edge between each pair of consecutive nodes
+// of path, failing the test if some pair is not joined.
+func pathCost(t *testing.T, g Graph, path []int64, cost func(int, int) int) int {
+	t.Helper()
+	total := 0
+	for i := 0; i+1 < len(path); i++ {
+		out, err := g.Neighbors(path[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		best := -1
+		for _, e := range out {
+			if c := cost(e.DistM, e.Speed); e.Dst == path[i+1] && (best < 0 || c < best) {
+				best = c
+			}
+		}
+		if best < 0 {
+			t.Fatalf("no edge %d -> %d in %v", path[i], path[i+1], path)
+		}
+		total += best
+	}
+	return total
+}
+
+func TestRoutersMatchDijkstra(t *testing.T) {
+	g := testGrid(20, 1)
+	reg := NewRegistry()
+	rnd := rand.New(rand.NewSource(2))
+	for range 100 {
+		src, dst := g.ids[rnd.Intn(len(g.ids))], g.ids[rnd.Intn(len(g.ids))]
+		for _, m := range []Metric{Distance, TravelTime, Blend(0.3)} {
+			wantPath, want, _, err := Dijkstra(g, src, dst, m.Cost)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, rt := range reg.List() {
+				res, err := rt.Route(g, Query{Src: src, Dst: dst, Metric: m, MaxSpeed: 90, Stretch: g.stretch()})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if (len(res.Path) == 0) != (len(wantPath) == 0) || res.Total != want {
+					t.Fatalf("%s %s %d -> %d: total %d, dijkstra %d", rt.Name(), m.Name, src, dst, res.Total, want)
+				}
+				if len(res.Path) > 0 && pathCost(t, g, res.Path, m.Cost) != want {
+					t.Fatalf("%s %s %d -> %d: path does not cost %d", rt.Name(), m.Name, src, dst, want)
+				}
+			}
+		}
+	}
+}
@@ -1,14 +1,34 @@
 package algo
 
 import (
+	"context"
+
 	"github.com/atharv3903/graphion/internal/cache"
 	"github.com/atharv3903/graphion/internal/db"
 	"github.com/atharv3903/graphion/internal/model"
 )
 
 type GraphCtx struct {
-	Store db.Store
-	Adj   *cache.AdjCache
+	Store  db.Store
+	Adj    *cache.AdjCache
+	RevAdj *cache.AdjCache // incoming edges, keyed by head node
+	Coords *cache.CoordCache
+	// Ctx cancels the MySQL reads of a search; nil means never.
+	Ctx context.Context
+}
+
+// WithContext returns a copy of g whose MySQL reads are abandoned once ctx
+// is done. The caches stay shared.
+func (g GraphCtx) WithContext(ctx context.Context) GraphCtx {
+	g.Ctx = ctx
+	return g
+}
+
+func (g GraphCtx) context() context.Context {
+	if g.Ctx == nil {
+		return context.Background()
+	}
+	return g.Ctx
 }
 
 func (g GraphCtx) Neighbors(n int64) ([]model.Edge, error) {
@@ -16,7 +36,7 @@ func (g GraphCtx) Neighbors(n int64) ([]model.Edge, error) {
 		return v, nil
 	}
 
-	edges, err := g.Store.Outgoing(n)
+	edges, err := g.Store.Outgoing(g.context(), n)
 	if err != nil {
 		return nil, err
 	}
@@ -24,3 +44,35 @@ func (g GraphCtx) Neighbors(n int64) ([]model.Edge, error) {
 	g.Adj.Put(n, edges)
 	return edges, nil
 }
+
+// InNeighbors returns the open edges ending at n, for searches that run
+// backwards from the target.
+func (g GraphCtx) InNeighbors(n int64) ([]model.Edge, error) {
+	if v, ok := g.RevAdj.Get(n); ok {
+		return v, nil
+	}
+
+	edges, err := g.Store.Incoming(g.context(), n)
+	if err != nil {
+		return nil, err
+	}
+
+	g.RevAdj.Put(n, edges)
+	return edges, nil
+}
+
+// Coord returns the position of n. ok is false for nodes without a row in
+// the nodes table.
+func (g GraphCtx) Coord(n int64) (model.Coord, bool, error) {
+	if c, ok := g.Coords.Get(n); ok {
+		return c, true, nil
+	}
+
+	c, ok, err := g.Store.NodeCoord(n)
+	if err != nil || !ok {
+		return model.Coord{}, false, err
+	}
+
+	g.Coords.Put(n, c)
+	return c, true, nil
+}
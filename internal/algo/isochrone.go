@@ -0,0 +1,45 @@
+package algo
+
+import "container/heap"
+
+// Reachable returns every node src reaches at a cost of at most budget,
+// with that cost.
+func Reachable(g Graph, src int64, budget int, cost func(int, int) int) (map[int64]int, int, error) {
+	dist := map[int64]int{src: 0}
+	settled := map[int64]int{}
+	pq := &pq{}
+	heap.Push(pq, pqItem{node: src, dist: 0})
+	explored := 0
+
+	for pq.Len() > 0 {
+		cur := heap.Pop(pq).(pqItem)
+		u := cur.node
+
+		if cur.dist > budget {
+			break
+		}
+		if cur.dist > dist[u] {
+			continue
+		}
+		settled[u] = cur.dist
+		explored++
+
+		neighbors, err := g.Neighbors(u)
+		if err != nil {
+			return nil, explored, err
+		}
+
+		for _, e := range neighbors {
+			nd := cur.dist + cost(e.DistM, e.Speed)
+			if nd > budget {
+				continue
+			}
+			if old, found := dist[e.Dst]; !found || nd < old {
+				dist[e.Dst] = nd
+				heap.Push(pq, pqItem{node: e.Dst, dist: nd})
+			}
+		}
+	}
+
+	return settled, explored, nil
+}
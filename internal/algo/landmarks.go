@@ -0,0 +1,335 @@
+package algo
+
+import (
+	"container/heap"
+	"encoding/gob"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"sync/atomic"
+
+	"github.com/atharv3903/graphion/internal/model"
+)
+
+// Landmark selection strategies for BuildLandmarks.
+const (
+	// LandmarksFarthest repeatedly picks the node farthest from every
+	// landmark chosen so far.
+	LandmarksFarthest = "farthest"
+	// LandmarksAvoid (Goldberg & Werneck) grows a shortest path tree from a
+	// random root and descends into the subtree whose nodes the current
+	// landmarks bound worst.
+	LandmarksAvoid = "avoid"
+)
+
+// unreachable marks a missing entry in the landmark tables.
+const unreachable = -1
+
+// Landmarks holds the ALT distance tables for one metric: for landmark i
+// and node v, from[i][v] = d(L_i, v) and to[i][v] = d(v, L_i).
+//
+// The bounds derived from them stay admissible when edges get more
+// expensive (closures, speed drops), but not when they get cheaper. Callers
+// MarkStale the tables on such updates and stop using them until they are
+// recomputed.
+type Landmarks struct {
+	Metric string
+	Nodes  []int64 // landmark node ids
+	// Edges is the EdgeFingerprint of the edges the tables were built from
+	Edges uint64
+
+	index map[int64]int32
+	from  [][]int32
+	to    [][]int32
+	stale atomic.Bool
+}
+
+func (l *Landmarks) MarkStale()  { l.stale.Store(true) }
+func (l *Landmarks) Stale() bool { return l.stale.Load() }
+
+// landmarkGraph is a dense copy of the edge list for the one-to-all
+// searches that landmark preprocessing needs.
+type landmarkGraph struct {
+	ids     []int64
+	index   map[int64]int32
+	out, in [][]landmarkArc
+}
+
+type landmarkArc struct {
+	to   int32
+	cost int
+}
+
+func newLandmarkGraph(edges []model.Edge, cost func(int, int) int) *landmarkGraph {
+	g := &landmarkGraph{index: map[int64]int32{}}
+	node := func(id int64) int32 {
+		if v, ok := g.index[id]; ok {
+			return v
+		}
+		v := int32(len(g.ids))
+		g.index[id] = v
+		g.ids = append(g.ids, id)
+		g.out = append(g.out, nil)
+		g.in = append(g.in, nil)
+		return v
+	}
+	for _, e := range edges {
+		u, v := node(e.Src), node(e.Dst)
+		c := cost(e.DistM, e.Speed)
+		g.out[u] = append(g.out[u], landmarkArc{to: v, cost: c})
+		g.in[v] = append(g.in[v], landmarkArc{to: u, cost: c})
+	}
+	return g
+}
+
+// oneToAll runs a full Dijkstra from root over adj. It returns the distance
+// table (unreachable where not reached), the tree parent of every reached
+// node and the nodes in settle order.
+func oneToAll(adj [][]landmarkArc, root int32) (dist []int32, parent []int32, order []int32) {
+	n := len(adj)
+	d := make([]int, n)
+	parent = make([]int32, n)
+	for i := range d {
+		d[i] = math.MaxInt
+		parent[i] = -1
+	}
+	d[root] = 0
+
+	pq := &pq{}
+	heap.Push(pq, pqItem{node: int64(root), dist: 0})
+	for pq.Len() > 0 {
+		cur := heap.Pop(pq).(pqItem)
+		u := int32(cur.node)
+		if cur.dist > d[u] {
+			continue
+		}
+		order = append(order, u)
+		for _, a := range adj[u] {
+			if nd := cur.dist + a.cost; nd < d[a.to] {
+				d[a.to] = nd
+				parent[a.to] = u
+				heap.Push(pq, pqItem{node: int64(a.to), dist: nd})
+			}
+		}
+	}
+
+	dist = make([]int32, n)
+	for i, v := range d {
+		switch {
+		case v == math.MaxInt:
+			dist[i] = unreachable
+		case v > math.MaxInt32:
+			dist[i] = math.MaxInt32
+		default:
+			dist[i] = int32(v)
+		}
+	}
+	return dist, parent, order
+}
+
+// BuildLandmarks selects count landmarks with the given strategy and
+// computes their distance tables over edges under m.
+func BuildLandmarks(m Metric, edges []model.Edge, count int, strategy string) (*Landmarks, error) {
+	g := newLandmarkGraph(edges, m.Cost)
+	l := &Landmarks{Metric: m.Name, Edges: EdgeFingerprint(edges, m), index: g.index}
+	if len(g.ids) == 0 {
+		return l, nil
+	}
+	count = min(count, len(g.ids))
+
+	rnd := rand.New(rand.NewSource(1))
+	chosen := map[int32]bool{}
+	add := func(v int32) {
+		chosen[v] = true
+		from, _, _ := oneToAll(g.out, v)
+		to, _, _ := oneToAll(g.in, v)
+		l.Nodes = append(l.Nodes, g.ids[v])
+		l.from = append(l.from, from)
+		l.to = append(l.to, to)
+	}
+
+	// avoid can come back empty handed from a root whose tree is already
+	// covered; give it a few other roots before settling for fewer landmarks
+	for tries := 0; len(l.Nodes) < count && tries < 10*count; tries++ {
+		var next int32
+		switch strategy {
+		case LandmarksFarthest:
+			next = g.farthest(l, chosen, int32(rnd.Intn(len(g.ids))))
+		case LandmarksAvoid:
+			next = g.avoid(l, chosen, int32(rnd.Intn(len(g.ids))))
+		default:
+			return nil, fmt.Errorf("unknown landmark strategy %q", strategy)
+		}
+		if next >= 0 {
+			add(next)
+		}
+	}
+
+	return l, nil
+}
+
+// farthest returns the unchosen node with the largest distance to its
+// nearest landmark. Nodes no landmark reaches count as infinitely far, so
+// disconnected pieces of the graph get covered too. The very first landmark
+// is the node farthest from start.
+func (g *landmarkGraph) farthest(l *Landmarks, chosen map[int32]bool, start int32) int32 {
+	if len(l.Nodes) == 0 {
+		dist, _, order := oneToAll(g.out, start)
+		best := order[len(order)-1]
+		for _, v := range order {
+			if dist[v] > dist[best] {
+				best = v
+			}
+		}
+		return best
+	}
+
+	best, bestD := int32(-1), int64(-1)
+	for v := range g.ids {
+		if chosen[int32(v)] {
+			continue
+		}
+		near := int64(math.MaxInt64)
+		for i := range l.Nodes {
+			if d := l.from[i][v]; d != unreachable {
+				near = min(near, int64(d))
+			}
+		}
+		if near > bestD {
+			best, bestD = int32(v), near
+		}
+	}
+	return best
+}
+
+// avoid grows a shortest path tree from root, weighs every node by how badly
+// the current landmarks bound its distance from root, and walks down the
+// heaviest landmark-free subtree to a leaf.
+func (g *landmarkGraph) avoid(l *Landmarks, chosen map[int32]bool, root int32) int32 {
+	dist, parent, order := oneToAll(g.out, root)
+
+	size := make([]int64, len(g.ids))
+	blocked := make([]bool, len(g.ids))
+	children := make([][]int32, len(g.ids))
+	for _, v := range order {
+		size[v] = int64(dist[v]) - int64(l.bound(root, v))
+		blocked[v] = chosen[v]
+		if p := parent[v]; p >= 0 {
+			children[p] = append(children[p], v)
+		}
+	}
+	// settle order lists parents before children, so walk it backwards
+	for i := len(order) - 1; i >= 0; i-- {
+		v := order[i]
+		if blocked[v] {
+			size[v] = 0
+		}
+		if p := parent[v]; p >= 0 {
+			size[p] += size[v]
+			blocked[p] = blocked[p] || blocked[v]
+		}
+	}
+
+	v := root
+	for {
+		next, nextSize := int32(-1), int64(0)
+		for _, c := range children[v] {
+			if !blocked[c] && size[c] > nextSize {
+				next, nextSize = c, size[c]
+			}
+		}
+		if next < 0 {
+			break
+		}
+		v = next
+	}
+	if chosen[v] {
+		return -1
+	}
+	return v
+}
+
+// bound is the landmark lower bound on d(v, t) for dense indices, using
+// every landmark.
+func (l *Landmarks) bound(v, t int32) int {
+	best := 0
+	for i := range l.Nodes {
+		best = max(best, l.boundVia(i, v, t))
+	}
+	return best
+}
+
+// boundVia is the triangle inequality bound on d(v, t) through landmark i:
+// d(v,t) >= d(L,t) - d(L,v) and d(v,t) >= d(v,L) - d(t,L).
+func (l *Landmarks) boundVia(i int, v, t int32) int {
+	best := 0
+	if lv, lt := l.from[i][v], l.from[i][t]; lv != unreachable && lt != unreachable {
+		best = max(best, int(lt)-int(lv))
+	}
+	if vl, tl := l.to[i][v], l.to[i][t]; vl != unreachable && tl != unreachable {
+		best = max(best, int(vl)-int(tl))
+	}
+	return best
+}
+
+type landmarkFile struct {
+	Metric string
+	Nodes  []int64
+	Edges  uint64
+	IDs    []int64 // dense index -> node id for the table columns
+	From   [][]int32
+	To     [][]int32
+}
+
+// SaveLandmarks writes the tables to path.
+func SaveLandmarks(path string, l *Landmarks) error {
+	ids := make([]int64, len(l.index))
+	for id, v := range l.index {
+		ids[v] = id
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := gob.NewEncoder(f).Encode(landmarkFile{
+		Metric: l.Metric,
+		Nodes:  l.Nodes,
+		Edges:  l.Edges,
+		IDs:    ids,
+		From:   l.from,
+		To:     l.to,
+	}); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// LoadLandmarks reads tables written by SaveLandmarks.
+func LoadLandmarks(path string) (*Landmarks, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lf landmarkFile
+	if err := gob.NewDecoder(f).Decode(&lf); err != nil {
+		return nil, err
+	}
+
+	l := &Landmarks{
+		Metric: lf.Metric,
+		Nodes:  lf.Nodes,
+		Edges:  lf.Edges,
+		index:  make(map[int64]int32, len(lf.IDs)),
+		from:   lf.From,
+		to:     lf.To,
+	}
+	for v, id := range lf.IDs {
+		l.index[id] = int32(v)
+	}
+	return l, nil
+}
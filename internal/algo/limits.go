@@ -0,0 +1,95 @@
+package algo
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+
+	"github.com/atharv3903/graphion/internal/model"
+)
+
+// ErrExploreBudget aborts a search that expanded more nodes than its
+// Limits allow.
+var ErrExploreBudget = errors.New("search exceeded its explored node budget")
+
+// Limits bounds the searches of one request: they stop once ctx is done or
+// once they have expanded maxExplored nodes between them.
+type Limits struct {
+	ctx      context.Context
+	max      int64
+	explored atomic.Int64
+}
+
+// NewLimits returns limits tied to ctx. maxExplored <= 0 means no budget.
+func NewLimits(ctx context.Context, maxExplored int) *Limits {
+	return &Limits{ctx: ctx, max: int64(maxExplored)}
+}
+
+// Explored is the number of nodes expanded so far.
+func (l *Limits) Explored() int { return int(l.explored.Load()) }
+
+// expand accounts for one expanded node and reports whether the search may
+// go on.
+func (l *Limits) expand() error {
+	if err := l.ctx.Err(); err != nil {
+		return err
+	}
+	if n := l.explored.Add(1); l.max > 0 && n > l.max {
+		return ErrExploreBudget
+	}
+	return nil
+}
+
+// Graph returns g with every expansion counted against l. Once the limits
+// are hit Neighbors and InNeighbors fail with ErrExploreBudget or ctx's
+// error, which ends whatever search is running. A DenseGraph stays one.
+func (l *Limits) Graph(g Graph) Graph {
+	lg := &limitedGraph{Graph: g, l: l}
+	if dg, ok := g.(DenseGraph); ok {
+		return &limitedDense{limitedGraph: lg, dense: dg}
+	}
+	return lg
+}
+
+type limitedGraph struct {
+	Graph
+	l *Limits
+}
+
+func (g *limitedGraph) Neighbors(n int64) ([]model.Edge, error) {
+	if err := g.l.expand(); err != nil {
+		return nil, err
+	}
+	return g.Graph.Neighbors(n)
+}
+
+func (g *limitedGraph) InNeighbors(n int64) ([]model.Edge, error) {
+	if err := g.l.expand(); err != nil {
+		return nil, err
+	}
+	return g.Graph.InNeighbors(n)
+}
+
+// limitedDense keeps the dense fast path; denseAStar calls expand itself
+// since Out cannot fail.
+type limitedDense struct {
+	*limitedGraph
+	dense DenseGraph
+}
+
+func (g *limitedDense) Len() int                              { return g.dense.Len() }
+func (g *limitedDense) Index(id int64) (uint32, bool)         { return g.dense.Index(id) }
+func (g *limitedDense) ID(v uint32) int64                     { return g.dense.ID(v) }
+func (g *limitedDense) Out(v uint32) ([]model.Edge, []uint32) { return g.dense.Out(v) }
+
+// Unlimited returns the graph under a Limits wrapper, for engines that
+// search their own data and only need to recognise the graph.
+func Unlimited(g Graph) Graph {
+	switch lg := g.(type) {
+	case *limitedDense:
+		return lg.dense
+	case *limitedGraph:
+		return lg.Graph
+	}
+	return g
+}
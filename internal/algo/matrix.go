@@ -0,0 +1,58 @@
+package algo
+
+import "container/heap"
+
+// Unreachable is the OneToMany cost of a target with no path from the source.
+const Unreachable = -1
+
+// OneToMany returns the cost from src to each of targets, in order, or
+// Unreachable. It is a single Dijkstra that stops once every target is
+// settled, rather than one search per target.
+func OneToMany(g Graph, src int64, targets []int64, cost func(int, int) int) ([]int, int, error) {
+	out := make([]int, len(targets))
+	pending := map[int64][]int{}
+	for i, t := range targets {
+		out[i] = Unreachable
+		pending[t] = append(pending[t], i)
+	}
+
+	dist := map[int64]int{src: 0}
+	pq := &pq{}
+	heap.Push(pq, pqItem{node: src, dist: 0})
+	explored := 0
+
+	for pq.Len() > 0 && len(pending) > 0 {
+		cur := heap.Pop(pq).(pqItem)
+		u := cur.node
+
+		if cur.dist > dist[u] {
+			continue
+		}
+		if idx, ok := pending[u]; ok {
+			for _, i := range idx {
+				out[i] = cur.dist
+			}
+			delete(pending, u)
+			if len(pending) == 0 {
+				break
+			}
+		}
+
+		explored++
+
+		neighbors, err := g.Neighbors(u)
+		if err != nil {
+			return nil, explored, err
+		}
+
+		for _, e := range neighbors {
+			nd := cur.dist + cost(e.DistM, e.Speed)
+			if old, found := dist[e.Dst]; !found || nd < old {
+				dist[e.Dst] = nd
+				heap.Push(pq, pqItem{node: e.Dst, dist: nd})
+			}
+		}
+	}
+
+	return out, explored, nil
+}
@@ -0,0 +1,63 @@
+package algo
+
+import (
+	"fmt"
+
+	"github.com/atharv3903/graphion/internal/model"
+)
+
+// bestEdge returns the u->v edge that cost prices lowest, which is the one
+// the searches would have taken.
+func bestEdge(g Graph, u, v int64, cost func(int, int) int) (model.Edge, bool, error) {
+	edges, err := g.Neighbors(u)
+	if err != nil {
+		return model.Edge{}, false, err
+	}
+
+	var best model.Edge
+	found := false
+	for _, e := range edges {
+		if e.Dst != v {
+			continue
+		}
+		if !found || cost(e.DistM, e.Speed) < cost(best.DistM, best.Speed) {
+			best, found = e, true
+		}
+	}
+	return best, found, nil
+}
+
+// PathTotals sums the length in meters and the travel time in milliseconds
+// along path.
+func PathTotals(g Graph, path []int64, cost func(int, int) int) (meters, ms int, err error) {
+	for i := 0; i+1 < len(path); i++ {
+		e, ok, err := bestEdge(g, path[i], path[i+1], cost)
+		if err != nil {
+			return 0, 0, err
+		}
+		if !ok {
+			return 0, 0, fmt.Errorf("no edge %d->%d on path", path[i], path[i+1])
+		}
+
+		meters += e.DistM
+		ms += TravelTimeCost(e.DistM, e.Speed)
+	}
+	return meters, ms, nil
+}
+
+// prefixCosts returns the cost of reaching each node of path from its
+// first node: out[0] is 0 and out[len(path)-1] is the path cost.
+func prefixCosts(g Graph, path []int64, cost func(int, int) int) ([]int, error) {
+	out := make([]int, len(path))
+	for i := 0; i+1 < len(path); i++ {
+		e, ok, err := bestEdge(g, path[i], path[i+1], cost)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("no edge %d->%d on path", path[i], path[i+1])
+		}
+		out[i+1] = out[i] + cost(e.DistM, e.Speed)
+	}
+	return out, nil
+}
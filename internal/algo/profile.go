@@ -0,0 +1,151 @@
+package algo
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/atharv3903/graphion/internal/model"
+)
+
+// Profile describes who is travelling: which edges they may use and how
+// fast they go on them.
+type Profile struct {
+	Name string
+	Mode model.Modes
+	// Classes are the highway classes open to the profile. Edges whose
+	// Allow has Mode are open regardless, and edges whose Deny has it are
+	// closed regardless.
+	Classes map[string]bool
+	// MaxSpeed caps edge speeds in km/h, 0 for no cap.
+	MaxSpeed int
+	// IgnoreOneway lets the profile use edges against oneway roads.
+	IgnoreOneway bool
+}
+
+// Allows reports whether the profile may use e.
+func (p Profile) Allows(e model.Edge) bool {
+	if e.Deny&p.Mode != 0 {
+		return false
+	}
+	if e.Allow&p.Mode != 0 {
+		return true
+	}
+	if e.AgainstOneway && !p.IgnoreOneway {
+		return false
+	}
+	return p.Classes[e.Highway]
+}
+
+// Adjust caps e's speed at the profile's.
+func (p Profile) Adjust(e model.Edge) model.Edge {
+	if p.MaxSpeed > 0 && e.Speed > p.MaxSpeed {
+		e.Speed = p.MaxSpeed
+	}
+	return e
+}
+
+// Edges returns the edges the profile may use, adjusted to it.
+func (p Profile) Edges(edges []model.Edge) []model.Edge {
+	out := make([]model.Edge, 0, len(edges))
+	for _, e := range edges {
+		if p.Allows(e) {
+			out = append(out, p.Adjust(e))
+		}
+	}
+	return out
+}
+
+// Graph returns the view of g the profile travels on.
+func (p Profile) Graph(g Graph) Graph {
+	return MapGraph(FilterGraph(g, p.Allows), p.Adjust)
+}
+
+func classes(names ...string) map[string]bool {
+	m := make(map[string]bool, len(names))
+	for _, n := range names {
+		m[n] = true
+	}
+	return m
+}
+
+// motorRoads are open to cars and trucks. "" covers edges imported before
+// highway classes were recorded.
+var motorRoads = []string{
+	"", "motorway", "motorway_link", "trunk", "trunk_link",
+	"primary", "primary_link", "secondary", "secondary_link",
+	"tertiary", "tertiary_link", "unclassified", "residential",
+	"living_street", "service", "road",
+}
+
+var (
+	Car = Profile{
+		Name:    "car",
+		Mode:    model.ModeCar,
+		Classes: classes(motorRoads...),
+	}
+	Truck = Profile{
+		Name:     "truck",
+		Mode:     model.ModeTruck,
+		Classes:  classes(motorRoads...),
+		MaxSpeed: 80,
+	}
+	Bicycle = Profile{
+		Name: "bicycle",
+		Mode: model.ModeBicycle,
+		Classes: classes("", "primary", "primary_link", "secondary", "secondary_link",
+			"tertiary", "tertiary_link", "unclassified", "residential", "living_street",
+			"service", "road", "cycleway", "path", "track"),
+		MaxSpeed: 18,
+	}
+	Foot = Profile{
+		Name: "foot",
+		Mode: model.ModeFoot,
+		Classes: classes("", "primary", "primary_link", "secondary", "secondary_link",
+			"tertiary", "tertiary_link", "unclassified", "residential", "living_street",
+			"service", "road", "cycleway", "path", "track", "footway", "pedestrian",
+			"steps", "bridleway"),
+		MaxSpeed:     5,
+		IgnoreOneway: true,
+	}
+)
+
+// DefaultProfile is used when a request names none. Preprocessed data
+// (contraction hierarchies, landmarks) is built for it.
+var DefaultProfile = Car
+
+var profiles = map[string]Profile{}
+
+// RegisterProfile makes p selectable by name through ParseProfile.
+func RegisterProfile(p Profile) {
+	profiles[p.Name] = p
+}
+
+func init() {
+	RegisterProfile(Car)
+	RegisterProfile(Truck)
+	RegisterProfile(Bicycle)
+	RegisterProfile(Foot)
+}
+
+// ParseProfile resolves a profile name as given on /route. Empty means
+// DefaultProfile.
+func ParseProfile(name string) (Profile, error) {
+	if name == "" {
+		return DefaultProfile, nil
+	}
+	p, ok := profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("unknown profile %q", name)
+	}
+	return p, nil
+}
+
+// ProfileNames lists the registered profiles.
+func ProfileNames() []string {
+	names := make([]string, 0, len(profiles))
+	for n := range profiles {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
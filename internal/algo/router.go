@@ -0,0 +1,138 @@
+package algo
+
+import (
+	"slices"
+	"sort"
+	"sync"
+)
+
+// Query is one point-to-point request handed to a Router.
+type Query struct {
+	Src, Dst int64
+	Metric   Metric
+	// MaxSpeed bounds every edge speed in km/h, for heuristics that need
+	// it. 0 means unknown.
+	MaxSpeed int
+	// Stretch lower-bounds every edge's length over the great-circle
+	// distance between its ends, see GeoHeuristic. 0 means unknown.
+	Stretch float64
+}
+
+type Result struct {
+	Path     []int64
+	Total    int
+	Explored int
+}
+
+// Capabilities is what a Router tells /algos and the request validation
+// about itself.
+type Capabilities struct {
+	// Metrics the engine can answer, empty for any metric.
+	Metrics []string `json:"metrics,omitempty"`
+	// Profiles the engine can answer, empty for any profile.
+	Profiles []string `json:"profiles,omitempty"`
+	// Preprocessing engines need data built ahead of queries.
+	Preprocessing bool `json:"preprocessing"`
+	// FixedGraph engines search their own data rather than the graph they
+	// are handed, so per-request edge filters don't reach them.
+	FixedGraph bool `json:"fixed_graph"`
+	// Ready is false while that data is missing or stale and queries are
+	// answered by a fallback search.
+	Ready bool `json:"ready"`
+}
+
+// Supports reports whether metric is one the engine can answer.
+func (c Capabilities) Supports(metric string) bool {
+	return len(c.Metrics) == 0 || slices.Contains(c.Metrics, metric)
+}
+
+// SupportsProfile reports whether profile is one the engine can answer.
+func (c Capabilities) SupportsProfile(profile string) bool {
+	return len(c.Profiles) == 0 || slices.Contains(c.Profiles, profile)
+}
+
+// Router is a shortest path engine selectable through /route?algo=.
+type Router interface {
+	Name() string
+	Capabilities() Capabilities
+	Route(g Graph, q Query) (Result, error)
+}
+
+// Registry holds the engines one server offers, by name.
+type Registry struct {
+	mu      sync.RWMutex
+	routers map[string]Router
+}
+
+// NewRegistry returns a registry with the engines that need no
+// preprocessing: dijkstra, astar and bidijkstra.
+func NewRegistry() *Registry {
+	r := &Registry{routers: map[string]Router{}}
+	r.Register(dijkstraRouter{})
+	r.Register(astarRouter{})
+	r.Register(bidijkstraRouter{})
+	return r
+}
+
+// Register adds rt, replacing any engine of the same name.
+func (r *Registry) Register(rt Router) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.routers[rt.Name()] = rt
+}
+
+func (r *Registry) Lookup(name string) (Router, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rt, ok := r.routers[name]
+	return rt, ok
+}
+
+// List returns the registered engines sorted by name.
+func (r *Registry) List() []Router {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]Router, 0, len(r.routers))
+	for _, rt := range r.routers {
+		out = append(out, rt)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name() < out[j].Name() })
+	return out
+}
+
+type dijkstraRouter struct{}
+
+func (dijkstraRouter) Name() string { return "dijkstra" }
+
+func (dijkstraRouter) Capabilities() Capabilities { return Capabilities{Ready: true} }
+
+func (dijkstraRouter) Route(g Graph, q Query) (Result, error) {
+	path, total, explored, err := Dijkstra(g, q.Src, q.Dst, q.Metric.Cost)
+	return Result{Path: path, Total: total, Explored: explored}, err
+}
+
+type astarRouter struct{}
+
+func (astarRouter) Name() string { return "astar" }
+
+func (astarRouter) Capabilities() Capabilities { return Capabilities{Ready: true} }
+
+func (astarRouter) Route(g Graph, q Query) (Result, error) {
+	h, err := GeoHeuristic(g, q.Dst, q.Metric, q.MaxSpeed, q.Stretch)
+	if err != nil {
+		return Result{}, err
+	}
+	path, total, explored, err := AStar(g, q.Src, q.Dst, q.Metric.Cost, h)
+	return Result{Path: path, Total: total, Explored: explored}, err
+}
+
+type bidijkstraRouter struct{}
+
+func (bidijkstraRouter) Name() string { return "bidijkstra" }
+
+func (bidijkstraRouter) Capabilities() Capabilities { return Capabilities{Ready: true} }
+
+func (bidijkstraRouter) Route(g Graph, q Query) (Result, error) {
+	path, total, explored, err := BidirectionalDijkstra(g, q.Src, q.Dst, q.Metric.Cost)
+	return Result{Path: path, Total: total, Explored: explored}, err
+}
@@ -0,0 +1,299 @@
+package algo
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/atharv3903/graphion/internal/model"
+)
+
+const (
+	// BucketMillis is the width of one traffic profile bucket.
+	BucketMillis = 15 * 60 * 1000
+	// WeekBuckets is the number of buckets in a profile, Monday 00:00
+	// first.
+	WeekBuckets = 7 * 24 * 60 * 60 * 1000 / BucketMillis
+)
+
+// Traffic holds the speed profiles edges refer to through Edge.Traffic:
+// per profile a speed in km/h for every bucket of the week in Location.
+// Edges without a profile, and buckets left at 0, use the edge's own speed,
+// which also caps the profile speeds so that road updates and vehicle
+// profile limits still apply. A nil *Traffic has no profiles.
+type Traffic struct {
+	Location *time.Location
+	speeds   map[int32][]int
+}
+
+// NewTraffic wraps profile speeds as loaded by db.Store.TrafficProfiles.
+func NewTraffic(speeds map[int32][]int, loc *time.Location) *Traffic {
+	return &Traffic{Location: loc, speeds: speeds}
+}
+
+// Len is the number of profiles.
+func (tr *Traffic) Len() int {
+	if tr == nil {
+		return 0
+	}
+	return len(tr.speeds)
+}
+
+// WeekMillis returns how far into its week t is, in ms since Monday 00:00
+// in tr.Location (UTC for a nil tr). It goes by the wall clock, so on a
+// day the clocks change 08:00 is still the 08:00 bucket.
+func (tr *Traffic) WeekMillis(t time.Time) int {
+	loc := time.UTC
+	if tr != nil && tr.Location != nil {
+		loc = tr.Location
+	}
+	t = t.In(loc)
+	day := (int(t.Weekday()) + 6) % 7
+	h, m, sec := t.Clock()
+	return ((day*24+h)*60+m)*60*1000 + sec*1000 + t.Nanosecond()/1e6
+}
+
+// profile returns the bucket speeds of e, nil if it has none.
+func (tr *Traffic) profile(e model.Edge) []int {
+	if tr == nil || e.Traffic == 0 {
+		return nil
+	}
+	return tr.speeds[e.Traffic]
+}
+
+// speedAt is e's speed in km/h during bucket b of profile p.
+func speedAt(e model.Edge, p []int, b int) int {
+	sp := e.Speed
+	if p[b] > 0 && p[b] < sp {
+		sp = p[b]
+	}
+	return max(sp, 1)
+}
+
+// bucket returns the bucket of the week t falls in and the time that
+// bucket starts, for any t including ones before the week's start.
+func bucket(t int) (int, int) {
+	q := t / BucketMillis
+	if t%BucketMillis < 0 {
+		q--
+	}
+	return (q%WeekBuckets + WeekBuckets) % WeekBuckets, q * BucketMillis
+}
+
+// TravelMillis is how long e takes when entered at week offset at (ms, see
+// WeekMillis). The speed changes wherever the traversal crosses a bucket
+// boundary instead of being fixed at entry, which keeps the exit time
+// non-decreasing in the entry time (FIFO): setting off later never gets
+// anyone there sooner.
+func (tr *Traffic) TravelMillis(e model.Edge, at int) int {
+	p := tr.profile(e)
+	if p == nil {
+		return TravelTimeCost(e.DistM, e.Speed)
+	}
+
+	left := float64(e.DistM)
+	for t := at; ; {
+		b, start := bucket(t)
+		sp := float64(speedAt(e, p, b))
+		end := start + BucketMillis
+		// km/h is meters per 3600 ms
+		reach := float64(end-t) * sp / 3600
+		if reach >= left {
+			return t - at + int(left*3600/sp)
+		}
+		left -= reach
+		t = end
+	}
+}
+
+// LatestMillis is how long before week offset exit e must be entered to
+// be left by exit: TravelMillis run backwards in time. Entering e that long
+// before exit, or earlier, gets through by exit.
+func (tr *Traffic) LatestMillis(e model.Edge, exit int) int {
+	p := tr.profile(e)
+	if p == nil {
+		return TravelTimeCost(e.DistM, e.Speed)
+	}
+
+	left := float64(e.DistM)
+	t := exit
+	for {
+		b, start := bucket(t - 1)
+		sp := float64(speedAt(e, p, b))
+		reach := float64(t-start) * sp / 3600
+		if reach >= left {
+			t -= int(math.Ceil(left * 3600 / sp))
+			break
+		}
+		left -= reach
+		t = start
+	}
+	// rounding may put the forward exit a millisecond late
+	for t+tr.TravelMillis(e, t) > exit {
+		t--
+	}
+	return exit - t
+}
+
+// PathMillis is the travel time along path when leaving at week offset at,
+// taking the quickest of parallel edges at the time they are reached.
+func (tr *Traffic) PathMillis(g Graph, path []int64, at int) (int, error) {
+	total := 0
+	for i := 0; i+1 < len(path); i++ {
+		edges, err := g.Neighbors(path[i])
+		if err != nil {
+			return 0, err
+		}
+		best := -1
+		for _, e := range edges {
+			if e.Dst != path[i+1] {
+				continue
+			}
+			if t := tr.TravelMillis(e, at+total); best < 0 || t < best {
+				best = t
+			}
+		}
+		if best < 0 {
+			return 0, fmt.Errorf("no edge %d -> %d", path[i], path[i+1])
+		}
+		total += best
+	}
+	return total, nil
+}
+
+// TimeDependent finds the earliest arrival at dst for a trip leaving src at
+// week offset at, charging every edge its TravelMillis at the moment it is
+// entered. FIFO edges make the first settling of a node its earliest
+// arrival, so this is Dijkstra over arrival times. h, if not nil, must
+// lower-bound the remaining travel time in ms and turns the search into
+// A*. It returns the path, the travel time in ms and the nodes explored.
+func TimeDependent(g Graph, src, dst int64, tr *Traffic, at int, h Heuristic) ([]int64, int, int, error) {
+	if h == nil {
+		h = ZeroHeuristic
+	}
+	hsrc, err := h(src)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	dist := map[int64]int{src: 0}
+	hval := map[int64]int{src: hsrc}
+	prev := map[int64]int64{}
+	pq := &pq{}
+	heap.Push(pq, pqItem{node: src, dist: hsrc})
+	explored := 0
+
+	for pq.Len() > 0 {
+		cur := heap.Pop(pq).(pqItem)
+		u := cur.node
+
+		if cur.dist > dist[u]+hval[u] {
+			continue
+		}
+		if u == dst {
+			break
+		}
+		explored++
+
+		neighbors, err := g.Neighbors(u)
+		if err != nil {
+			return nil, 0, explored, err
+		}
+
+		for _, e := range neighbors {
+			nd := dist[u] + tr.TravelMillis(e, at+dist[u])
+
+			old, found := dist[e.Dst]
+			if found && nd >= old {
+				continue
+			}
+			if !found {
+				if hval[e.Dst], err = h(e.Dst); err != nil {
+					return nil, 0, explored, err
+				}
+			}
+
+			dist[e.Dst] = nd
+			prev[e.Dst] = u
+			heap.Push(pq, pqItem{node: e.Dst, dist: nd + hval[e.Dst]})
+		}
+	}
+
+	if _, ok := dist[dst]; !ok {
+		return nil, 0, explored, nil
+	}
+
+	return buildPath(prev, src, dst), dist[dst], explored, nil
+}
+
+// LatestDeparture is TimeDependent run backwards from dst over incoming
+// edges: it finds the latest week offset src can be left at to reach dst
+// by week offset by. Since every edge is FIFO, leaving src then and
+// following the path gets there by then. h, if not nil, must lower-bound
+// the travel time from src to a node. It returns the path, how long before
+// by src must be left in ms and the nodes explored.
+func LatestDeparture(g Graph, src, dst int64, tr *Traffic, by int, h Heuristic) ([]int64, int, int, error) {
+	if h == nil {
+		h = ZeroHeuristic
+	}
+	hdst, err := h(dst)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	// dist is how long before by a node must be left
+	dist := map[int64]int{dst: 0}
+	hval := map[int64]int{dst: hdst}
+	next := map[int64]int64{}
+	pq := &pq{}
+	heap.Push(pq, pqItem{node: dst, dist: hdst})
+	explored := 0
+
+	for pq.Len() > 0 {
+		cur := heap.Pop(pq).(pqItem)
+		v := cur.node
+
+		if cur.dist > dist[v]+hval[v] {
+			continue
+		}
+		if v == src {
+			break
+		}
+		explored++
+
+		incoming, err := g.InNeighbors(v)
+		if err != nil {
+			return nil, 0, explored, err
+		}
+
+		for _, e := range incoming {
+			nd := dist[v] + tr.LatestMillis(e, by-dist[v])
+
+			old, found := dist[e.Src]
+			if found && nd >= old {
+				continue
+			}
+			if !found {
+				if hval[e.Src], err = h(e.Src); err != nil {
+					return nil, 0, explored, err
+				}
+			}
+
+			dist[e.Src] = nd
+			next[e.Src] = v
+			heap.Push(pq, pqItem{node: e.Src, dist: nd + hval[e.Src]})
+		}
+	}
+
+	if _, ok := dist[src]; !ok {
+		return nil, 0, explored, nil
+	}
+
+	path := []int64{src}
+	for n := src; n != dst; {
+		n = next[n]
+		path = append(path, n)
+	}
+	return path, dist[src], explored, nil
+}
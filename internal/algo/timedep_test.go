@@ -0,0 +1,170 @@
+package algo
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/atharv3903/graphion/internal/model"
+)
+
+const weekMillis = 7 * 24 * 60 * 60 * 1000
+
+// testTraffic gives the edges of g one of five random week profiles, or
+// none for every sixth edge.
+func testTraffic(g *testGraph, seed int64) (Graph, *Traffic) {
+	rnd := rand.New(rand.NewSource(seed))
+	speeds := map[int32][]int{}
+	for id := int32(1); id <= 5; id++ {
+		p := make([]int, WeekBuckets)
+		for b := range p {
+			if rnd.Intn(4) > 0 {
+				p[b] = []int{5, 10, 20, 40, 80}[rnd.Intn(5)]
+			}
+		}
+		speeds[id] = p
+	}
+	mg := MapGraph(g, func(e model.Edge) model.Edge {
+		e.Traffic = int32(e.ID % 6)
+		return e
+	})
+	return mg, NewTraffic(speeds, time.UTC)
+}
+
+func TestTimeDependentWithoutTrafficMatchesDijkstra(t *testing.T) {
+	g := testGrid(15, 9)
+	rnd := rand.New(rand.NewSource(1))
+	for range 50 {
+		src, dst := g.ids[rnd.Intn(len(g.ids))], g.ids[rnd.Intn(len(g.ids))]
+		_, want, _, _ := Dijkstra(g, src, dst, TravelTimeCost)
+		if _, got, _, _ := TimeDependent(g, src, dst, nil, rnd.Intn(weekMillis), nil); got != want {
+			t.Fatalf("%d -> %d: %d, dijkstra %d", src, dst, got, want)
+		}
+	}
+}
+
+func TestTimeDependentMatchesLabelCorrecting(t *testing.T) {
+	tg := testGrid(12, 9)
+	g, tr := testTraffic(tg, 1)
+	h := func(dst int64) Heuristic {
+		h, err := GeoHeuristic(g, dst, TravelTime, 90, tg.stretch())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return h
+	}
+	rnd := rand.New(rand.NewSource(4))
+	for range 30 {
+		src, dst := tg.ids[rnd.Intn(len(tg.ids))], tg.ids[rnd.Intn(len(tg.ids))]
+		at := rnd.Intn(weekMillis)
+
+		// earliest arrival by relaxing every edge until nothing improves
+		arrive := map[int64]int{src: 0}
+		for changed := true; changed; {
+			changed = false
+			for _, n := range tg.ids {
+				a, ok := arrive[n]
+				if !ok {
+					continue
+				}
+				out, _ := g.Neighbors(n)
+				for _, e := range out {
+					next := a + tr.TravelMillis(e, at+a)
+					if old, ok := arrive[e.Dst]; !ok || next < old {
+						arrive[e.Dst], changed = next, true
+					}
+				}
+			}
+		}
+
+		for _, hh := range []Heuristic{nil, h(dst)} {
+			path, got, _, err := TimeDependent(g, src, dst, tr, at, hh)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, ok := arrive[dst]
+			if !ok {
+				if path != nil {
+					t.Fatalf("%d -> %d: path where there is none", src, dst)
+				}
+				continue
+			}
+			if got != want {
+				t.Fatalf("%d -> %d at %d: %d ms, want %d", src, dst, at, got, want)
+			}
+			if ms, err := tr.PathMillis(g, path, at); err != nil || ms != got {
+				t.Fatalf("%d -> %d: path takes %d ms, search said %d", src, dst, ms, got)
+			}
+		}
+	}
+}
+
+func TestLatestDepartureMatchesLabelCorrecting(t *testing.T) {
+	tg := testGrid(12, 11)
+	g, tr := testTraffic(tg, 2)
+	rnd := rand.New(rand.NewSource(5))
+	for range 30 {
+		src, dst := tg.ids[rnd.Intn(len(tg.ids))], tg.ids[rnd.Intn(len(tg.ids))]
+		by := rnd.Intn(weekMillis)
+
+		// least time needed before by, relaxing backwards from dst
+		need := map[int64]int{dst: 0}
+		for changed := true; changed; {
+			changed = false
+			for _, n := range tg.ids {
+				a, ok := need[n]
+				if !ok {
+					continue
+				}
+				in, _ := g.InNeighbors(n)
+				for _, e := range in {
+					next := a + tr.LatestMillis(e, by-a)
+					if old, ok := need[e.Src]; !ok || next < old {
+						need[e.Src], changed = next, true
+					}
+				}
+			}
+		}
+
+		path, got, _, err := LatestDeparture(g, src, dst, tr, by, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, ok := need[src]
+		if !ok {
+			if path != nil {
+				t.Fatalf("%d -> %d: path where there is none", src, dst)
+			}
+			continue
+		}
+		if got != want {
+			t.Fatalf("%d -> %d by %d: leaves %d ms before, want %d", src, dst, by, got, want)
+		}
+		// driven forwards from that departure, the path arrives in time
+		if ms, err := tr.PathMillis(g, path, by-got); err != nil || by-got+ms > by {
+			t.Fatalf("%d -> %d: arrives %d ms late", src, dst, by-got+ms-by)
+		}
+	}
+}
+
+func TestWeekMillisFollowsWallClock(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("no time zone data:", err)
+	}
+	tr := NewTraffic(nil, loc)
+	for _, tc := range []struct {
+		at   time.Time
+		want int
+	}{
+		// clocks go forward at 02:00 that Sunday
+		{time.Date(2025, 3, 30, 8, 0, 0, 0, loc), (6*24 + 8) * 3600 * 1000},
+		// and back at 03:00 on this one
+		{time.Date(2025, 10, 26, 20, 30, 0, 0, loc), ((6*24+20)*60 + 30) * 60 * 1000},
+		{time.Date(2025, 3, 31, 8, 15, 30, 250e6, loc), (8*3600+15*60+30)*1000 + 250},
+	} {
+		if got := tr.WeekMillis(tc.at); got != tc.want {
+			t.Errorf("%v: %d, want %d", tc.at, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package algo
+
+import "errors"
+
+// TripEnd says where a trip finishes.
+type TripEnd int
+
+const (
+	// EndAnywhere leaves the last stop to the solver.
+	EndAnywhere TripEnd = iota
+	// EndAtLast finishes at the last stop.
+	EndAtLast
+	// EndAtStart returns to the first stop.
+	EndAtStart
+)
+
+// exactTripStops is the largest trip solved exactly; the DP is
+// O(2^n * n^2).
+const exactTripStops = 12
+
+// tripInf stands in for Unreachable costs. It is small enough that a
+// whole tour of them can't overflow.
+const tripInf = 1 << 40
+
+var ErrNoTrip = errors.New("no trip visits every stop")
+
+// SolveTrip orders stops 0..n-1 given cost[i][j] from stop i to stop j
+// (Unreachable where there is no path). The trip starts at stop 0; end
+// picks where it finishes. Small trips are solved exactly, larger ones by
+// nearest neighbour improved with 2-opt and Or-opt. The result is the
+// visiting order and its total cost, including the way back to stop 0 for
+// EndAtStart; the returned order does not repeat stop 0 at the end.
+func SolveTrip(cost [][]int, end TripEnd) ([]int, int, error) {
+	n := len(cost)
+	if n == 0 {
+		return nil, 0, nil
+	}
+
+	c := func(i, j int) int {
+		if cost[i][j] == Unreachable {
+			return tripInf
+		}
+		return cost[i][j]
+	}
+
+	var order []int
+	if n <= exactTripStops {
+		order = exactTrip(n, c, end)
+	} else {
+		order = nearestNeighbour(n, c, end)
+		improveTrip(order, c, end)
+	}
+
+	total := tripCost(order, c, end)
+	if total >= tripInf {
+		return nil, 0, ErrNoTrip
+	}
+	return order, total, nil
+}
+
+func tripCost(order []int, c func(int, int) int, end TripEnd) int {
+	total := 0
+	for i := 0; i+1 < len(order); i++ {
+		total += c(order[i], order[i+1])
+	}
+	if end == EndAtStart && len(order) > 1 {
+		total += c(order[len(order)-1], order[0])
+	}
+	return total
+}
+
+// exactTrip is Held-Karp over the stops between the fixed first (and, for
+// EndAtLast, last) stop.
+func exactTrip(n int, c func(int, int) int, end TripEnd) []int {
+	last := -1
+	if end == EndAtLast && n > 1 {
+		last = n - 1
+	}
+	var free []int
+	for i := 1; i < n; i++ {
+		if i != last {
+			free = append(free, i)
+		}
+	}
+	m := len(free)
+	if m == 0 {
+		if last >= 0 {
+			return []int{0, last}
+		}
+		return []int{0}
+	}
+
+	// best[mask][j]: cheapest way from stop 0 through the free stops in
+	// mask, ending at free[j]
+	full := 1<<m - 1
+	best := make([][]int, full+1)
+	from := make([][]int8, full+1)
+	for mask := range best {
+		best[mask] = make([]int, m)
+		from[mask] = make([]int8, m)
+		for j := range best[mask] {
+			best[mask][j] = -1
+		}
+	}
+	for j := range free {
+		best[1<<j][j] = c(0, free[j])
+		from[1<<j][j] = -1
+	}
+	for mask := 1; mask <= full; mask++ {
+		for j := 0; j < m; j++ {
+			if best[mask][j] < 0 {
+				continue
+			}
+			for k := 0; k < m; k++ {
+				if mask&(1<<k) != 0 {
+					continue
+				}
+				next := mask | 1<<k
+				d := best[mask][j] + c(free[j], free[k])
+				if best[next][k] < 0 || d < best[next][k] {
+					best[next][k], from[next][k] = d, int8(j)
+				}
+			}
+		}
+	}
+
+	closing := func(j int) int {
+		switch {
+		case last >= 0:
+			return c(free[j], last)
+		case end == EndAtStart:
+			return c(free[j], 0)
+		}
+		return 0
+	}
+	bj := 0
+	for j := 1; j < m; j++ {
+		if best[full][j]+closing(j) < best[full][bj]+closing(bj) {
+			bj = j
+		}
+	}
+
+	order := make([]int, 0, n)
+	for mask, j := full, bj; j >= 0; {
+		order = append(order, free[j])
+		pj := int(from[mask][j])
+		mask &^= 1 << j
+		j = pj
+	}
+	order = append(order, 0)
+	for i, k := 0, len(order)-1; i < k; i, k = i+1, k-1 {
+		order[i], order[k] = order[k], order[i]
+	}
+	if last >= 0 {
+		order = append(order, last)
+	}
+	return order
+}
+
+func nearestNeighbour(n int, c func(int, int) int, end TripEnd) []int {
+	last := -1
+	if end == EndAtLast {
+		last = n - 1
+	}
+	visited := make([]bool, n)
+	visited[0] = true
+	if last >= 0 {
+		visited[last] = true
+	}
+
+	order := []int{0}
+	for cur := 0; ; {
+		next := -1
+		for j := 0; j < n; j++ {
+			if !visited[j] && (next < 0 || c(cur, j) < c(cur, next)) {
+				next = j
+			}
+		}
+		if next < 0 {
+			break
+		}
+		visited[next] = true
+		order = append(order, next)
+		cur = next
+	}
+	if last >= 0 {
+		order = append(order, last)
+	}
+	return order
+}
+
+// improveTrip applies improving 2-opt and Or-opt moves to order in place
+// until neither finds one. The first stop, and the last for EndAtLast,
+// stay where they are. Costs may be asymmetric, so every move is priced
+// on the whole trip.
+func improveTrip(order []int, c func(int, int) int, end TripEnd) {
+	lo, hi := 1, len(order)
+	if end == EndAtLast {
+		hi--
+	}
+	cur := tripCost(order, c, end)
+	buf := make([]int, len(order))
+
+	for improved := true; improved; {
+		improved = false
+
+		// 2-opt: reverse order[i:j]
+		for i := lo; i < hi; i++ {
+			for j := i + 2; j <= hi; j++ {
+				reverse(order[i:j])
+				if d := tripCost(order, c, end); d < cur {
+					cur, improved = d, true
+				} else {
+					reverse(order[i:j])
+				}
+			}
+		}
+
+		// Or-opt: move a run of 1 to 3 stops elsewhere
+		for size := 1; size <= 3; size++ {
+			for i := lo; i+size <= hi; i++ {
+				for k := lo; k+size <= hi; k++ {
+					if k == i {
+						continue
+					}
+					copy(buf, order)
+					moveRun(buf[lo:hi], i-lo, size, k-lo)
+					if d := tripCost(buf, c, end); d < cur {
+						copy(order, buf)
+						cur, improved = d, true
+					}
+				}
+			}
+		}
+	}
+}
+
+func reverse(s []int) {
+	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+		s[i], s[j] = s[j], s[i]
+	}
+}
+
+// moveRun moves s[i:i+size] so that it starts at index k of the result.
+func moveRun(s []int, i, size, k int) {
+	run := append([]int(nil), s[i:i+size]...)
+	rest := append(append([]int(nil), s[:i]...), s[i+size:]...)
+	out := append(append(append(s[:0:0], rest[:k]...), run...), rest[k:]...)
+	copy(s, out)
+}
@@ -0,0 +1,175 @@
+package algo
+
+import (
+	"container/heap"
+	"strings"
+
+	"github.com/atharv3903/graphion/internal/model"
+)
+
+// Turns decides which turns an edge-based search may take and what they
+// cost on top of the edges.
+type Turns struct {
+	banned map[[2]int64]bool
+	// only[from] lists the sole edges that may follow from
+	only map[int64][]int64
+
+	// UTurnSeconds is charged for turning back along the edge just
+	// travelled; negative forbids U-turns.
+	UTurnSeconds float64
+}
+
+// NewTurns indexes restrictions by edge id.
+func NewTurns(restrictions []model.TurnRestriction, uturnSeconds float64) *Turns {
+	t := &Turns{
+		banned:       map[[2]int64]bool{},
+		only:         map[int64][]int64{},
+		UTurnSeconds: uturnSeconds,
+	}
+	for _, r := range restrictions {
+		switch {
+		case strings.HasPrefix(r.Type, "no_"):
+			t.banned[[2]int64{r.FromEdge, r.ToEdge}] = true
+		case strings.HasPrefix(r.Type, "only_"):
+			t.only[r.FromEdge] = append(t.only[r.FromEdge], r.ToEdge)
+		}
+	}
+	return t
+}
+
+// Len is the number of restricted edge pairs plus mandatory turns.
+func (t *Turns) Len() int {
+	n := len(t.banned)
+	for _, to := range t.only {
+		n += len(to)
+	}
+	return n
+}
+
+// Cost returns the extra cost of leaving from onto to, and false when the
+// turn is not allowed.
+func (t *Turns) Cost(from, to model.Edge, cost func(int, int) int) (int, bool) {
+	if t.banned[[2]int64{from.ID, to.ID}] {
+		return 0, false
+	}
+	if only, ok := t.only[from.ID]; ok && !containsID(only, to.ID) {
+		return 0, false
+	}
+	if to.Dst == from.Src {
+		if t.UTurnSeconds < 0 {
+			return 0, false
+		}
+		return secondsCost(t.UTurnSeconds, cost), true
+	}
+	return 0, true
+}
+
+func containsID(ids []int64, id int64) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// secondsCost prices a delay of sec seconds in cost's unit, as the cost of
+// driving for that long at blendRefSpeed. Under TravelTime that is exactly
+// sec in ms.
+func secondsCost(sec float64, cost func(int, int) int) int {
+	return cost(int(sec*blendRefSpeed/3.6), blendRefSpeed)
+}
+
+// EdgeDijkstra is Dijkstra over edges instead of nodes, so that the cost
+// of entering an edge can depend on the edge it is entered from. It
+// returns the node sequence like Dijkstra; explored counts settled edges.
+func EdgeDijkstra(g Graph, src, dst int64, cost func(int, int) int, t *Turns) ([]int64, int, int, error) {
+	if src == dst {
+		return []int64{src}, 0, 0, nil
+	}
+
+	// search state is keyed by edge id; prev[e] is the edge before e, with
+	// the edges leaving src having none
+	edges := map[int64]model.Edge{}
+	dist := map[int64]int{}
+	prev := map[int64]int64{}
+	pq := &pq{}
+
+	out, err := g.Neighbors(src)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	for _, e := range out {
+		d := cost(e.DistM, e.Speed)
+		if old, ok := dist[e.ID]; !ok || d < old {
+			edges[e.ID], dist[e.ID] = e, d
+			heap.Push(pq, pqItem{node: e.ID, dist: d})
+		}
+	}
+
+	explored := 0
+	last, found := int64(0), false
+
+	for pq.Len() > 0 {
+		cur := heap.Pop(pq).(pqItem)
+		if cur.dist > dist[cur.node] {
+			continue
+		}
+		e := edges[cur.node]
+		if e.Dst == dst {
+			last, found = e.ID, true
+			break
+		}
+		explored++
+
+		next, err := g.Neighbors(e.Dst)
+		if err != nil {
+			return nil, 0, explored, err
+		}
+		for _, f := range next {
+			tc, ok := t.Cost(e, f, cost)
+			if !ok {
+				continue
+			}
+			nd := cur.dist + tc + cost(f.DistM, f.Speed)
+			if old, seen := dist[f.ID]; !seen || nd < old {
+				edges[f.ID], dist[f.ID], prev[f.ID] = f, nd, e.ID
+				heap.Push(pq, pqItem{node: f.ID, dist: nd})
+			}
+		}
+	}
+
+	if !found {
+		return nil, 0, explored, nil
+	}
+
+	path := []int64{dst}
+	for id := last; ; {
+		e := edges[id]
+		path = append(path, e.Src)
+		p, ok := prev[id]
+		if !ok {
+			break
+		}
+		id = p
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+
+	return path, dist[last], explored, nil
+}
+
+// TurnRouter is the edge-based engine behind algo=turns.
+type TurnRouter struct {
+	Turns *Turns
+}
+
+func (TurnRouter) Name() string { return "turns" }
+
+func (TurnRouter) Capabilities() Capabilities { return Capabilities{Ready: true} }
+
+func (r TurnRouter) Route(g Graph, q Query) (Result, error) {
+	path, total, explored, err := EdgeDijkstra(g, q.Src, q.Dst, q.Metric.Cost, r.Turns)
+	return Result{Path: path, Total: total, Explored: explored}, err
+}
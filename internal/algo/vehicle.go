@@ -0,0 +1,46 @@
+package algo
+
+import "github.com/atharv3903/graphion/internal/model"
+
+// Blocks returns the restriction that keeps v off e, or "" if v may use
+// it. Limits the vehicle gives no size for don't apply.
+func Blocks(v model.Vehicle, e model.Edge) (string, float64) {
+	switch {
+	case e.MaxHeight > 0 && v.Height > e.MaxHeight:
+		return "maxheight", e.MaxHeight
+	case e.MaxWeight > 0 && v.Weight > e.MaxWeight:
+		return "maxweight", e.MaxWeight
+	case e.MaxWidth > 0 && v.Width > e.MaxWidth:
+		return "maxwidth", e.MaxWidth
+	case e.NoHazmat && v.Hazmat:
+		return "hazmat", 0
+	}
+	return "", 0
+}
+
+// VehicleGraph returns g without the edges v may not use.
+func VehicleGraph(g Graph, v model.Vehicle) Graph {
+	return FilterGraph(g, func(e model.Edge) bool {
+		r, _ := Blocks(v, e)
+		return r == ""
+	})
+}
+
+// BlockedEdges lists the edges along path that v may not use, taking the
+// edge cost prices lowest between each pair of nodes.
+func BlockedEdges(g Graph, path []int64, v model.Vehicle, cost func(int, int) int) ([]model.BlockedEdge, error) {
+	var out []model.BlockedEdge
+	for i := 0; i+1 < len(path); i++ {
+		e, ok, err := bestEdge(g, path[i], path[i+1], cost)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		if r, limit := Blocks(v, e); r != "" {
+			out = append(out, model.BlockedEdge{EdgeID: e.ID, Restriction: r, Limit: limit})
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,174 @@
+package algo
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/atharv3903/graphion/internal/model"
+)
+
+// Virtual is a node partway along the road between nodes A and B, for
+// searches that start or end where a query point was projected onto it.
+// Frac is its position from A (0) to B (1).
+type Virtual struct {
+	ID    int64
+	A, B  int64
+	Frac  float64
+	Coord model.Coord
+}
+
+// virtualBits is the low bits of a piece id numbering the virtual node it
+// enters.
+const virtualBits = 8
+
+// virtualGraph is a Graph with virtual nodes spliced into its roads.
+type virtualGraph struct {
+	Graph
+	out, in map[int64][]model.Edge
+	nodes   map[int64]Virtual
+}
+
+// WithVirtual returns g with the virtual nodes vs added. Each is joined to
+// A and B by the pieces of the edges between them it lies on, in whichever
+// directions those edges run, with their lengths cut in proportion; two
+// virtual nodes on the same road are also joined directly. The pieces
+// leaving a virtual node keep the id of the edge they are cut from, so turn
+// restrictions at its far end still apply. The pieces entering one get a
+// negative id of their own, which keeps them apart from the whole edge in
+// searches that key their state by edge; SourceEdge maps it back.
+func WithVirtual(g Graph, vs ...Virtual) (Graph, error) {
+	if len(vs) > 1<<virtualBits {
+		return nil, fmt.Errorf("at most %d virtual nodes", 1<<virtualBits)
+	}
+	vg := &virtualGraph{
+		Graph: g,
+		out:   map[int64][]model.Edge{},
+		in:    map[int64][]model.Edge{},
+		nodes: map[int64]Virtual{},
+	}
+	for _, v := range vs {
+		if _, dup := vg.nodes[v.ID]; dup {
+			return nil, fmt.Errorf("virtual node %d given twice", v.ID)
+		}
+		vg.nodes[v.ID] = v
+	}
+
+	// into is the id of the pieces of e entering virtual node id
+	slot := map[int64]int64{}
+	for i, v := range vs {
+		slot[v.ID] = int64(i)
+	}
+	into := func(e model.Edge, id int64) int64 { return -(e.ID<<virtualBits | slot[id]) }
+
+	for _, v := range vs {
+		ab, err := between(g, v.A, v.B)
+		if err != nil {
+			return nil, err
+		}
+		ba, err := between(g, v.B, v.A)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range ab {
+			vg.add(cut(e, v.ID, e.Dst, 1-v.Frac, e.ID))
+			vg.add(cut(e, e.Src, v.ID, v.Frac, into(e, v.ID)))
+		}
+		for _, e := range ba {
+			vg.add(cut(e, v.ID, e.Dst, v.Frac, e.ID))
+			vg.add(cut(e, e.Src, v.ID, 1-v.Frac, into(e, v.ID)))
+		}
+
+		for _, w := range vs {
+			if w.ID == v.ID {
+				continue
+			}
+			// w's position in v's direction along the road
+			var f float64
+			switch {
+			case w.A == v.A && w.B == v.B:
+				f = w.Frac
+			case w.A == v.B && w.B == v.A:
+				f = 1 - w.Frac
+			default:
+				continue
+			}
+			if f >= v.Frac {
+				for _, e := range ab {
+					vg.add(cut(e, v.ID, w.ID, f-v.Frac, into(e, w.ID)))
+				}
+			}
+			if f <= v.Frac {
+				for _, e := range ba {
+					vg.add(cut(e, v.ID, w.ID, v.Frac-f, into(e, w.ID)))
+				}
+			}
+		}
+	}
+	return vg, nil
+}
+
+// SourceEdge is the id of the edge a WithVirtual piece numbered id was cut
+// from, and id itself for any other edge.
+func SourceEdge(id int64) int64 {
+	if id < 0 {
+		return -id >> virtualBits
+	}
+	return id
+}
+
+// between returns the edges of g from a to b.
+func between(g Graph, a, b int64) ([]model.Edge, error) {
+	edges, err := g.Neighbors(a)
+	if err != nil {
+		return nil, err
+	}
+	var out []model.Edge
+	for _, e := range edges {
+		if e.Dst == b {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+// cut is the share of e running from src to dst.
+func cut(e model.Edge, src, dst int64, share float64, id int64) model.Edge {
+	e.ID, e.Src, e.Dst = id, src, dst
+	e.DistM = int(math.Round(float64(e.DistM) * share))
+	return e
+}
+
+func (vg *virtualGraph) add(e model.Edge) {
+	vg.out[e.Src] = append(vg.out[e.Src], e)
+	vg.in[e.Dst] = append(vg.in[e.Dst], e)
+}
+
+func (vg *virtualGraph) Neighbors(n int64) ([]model.Edge, error) {
+	if _, ok := vg.nodes[n]; ok {
+		return vg.out[n], nil
+	}
+	edges, err := vg.Graph.Neighbors(n)
+	if err != nil || len(vg.out[n]) == 0 {
+		return edges, err
+	}
+	return slices.Concat(edges, vg.out[n]), nil
+}
+
+func (vg *virtualGraph) InNeighbors(n int64) ([]model.Edge, error) {
+	if _, ok := vg.nodes[n]; ok {
+		return vg.in[n], nil
+	}
+	edges, err := vg.Graph.InNeighbors(n)
+	if err != nil || len(vg.in[n]) == 0 {
+		return edges, err
+	}
+	return slices.Concat(edges, vg.in[n]), nil
+}
+
+func (vg *virtualGraph) Coord(n int64) (model.Coord, bool, error) {
+	if v, ok := vg.nodes[n]; ok {
+		return v.Coord, true, nil
+	}
+	return vg.Graph.Coord(n)
+}
@@ -0,0 +1,117 @@
+package algo
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/atharv3903/graphion/internal/model"
+)
+
+func TestWithVirtualMatchesPieces(t *testing.T) {
+	g := testGrid(12, 9)
+	edges := g.edges()
+
+	// ends lists where a virtual node at frac along a -> b leads to (out)
+	// or comes from, with the cost of the piece of road in between
+	type end struct {
+		node int64
+		cost int
+	}
+	ends := func(a, b int64, frac float64, out bool) []end {
+		var r []end
+		piece := func(d int, share float64) int { return int(math.Round(float64(d) * share)) }
+		for _, e := range edges {
+			switch {
+			case e.Src == a && e.Dst == b && out:
+				r = append(r, end{b, piece(e.DistM, 1-frac)})
+			case e.Src == a && e.Dst == b:
+				r = append(r, end{a, piece(e.DistM, frac)})
+			case e.Src == b && e.Dst == a && out:
+				r = append(r, end{a, piece(e.DistM, frac)})
+			case e.Src == b && e.Dst == a:
+				r = append(r, end{b, piece(e.DistM, 1-frac)})
+			}
+		}
+		return r
+	}
+
+	rnd := rand.New(rand.NewSource(4))
+	for k := range 200 {
+		a, b := edges[rnd.Intn(len(edges))], edges[rnd.Intn(len(edges))]
+		if k%5 == 0 {
+			b = a
+		}
+		sameRoad := a.Src == b.Src && a.Dst == b.Dst || a.Src == b.Dst && a.Dst == b.Src
+		from := Virtual{ID: -1, A: a.Src, B: a.Dst, Frac: rnd.Float64()}
+		to := Virtual{ID: -2, A: b.Dst, B: b.Src, Frac: rnd.Float64()}
+		vg, err := WithVirtual(g, from, to)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// cheapest way off the first road, across the graph and onto the
+		// second; on a shared road the direct piece may beat it
+		want := -1
+		for _, s := range ends(from.A, from.B, from.Frac, true) {
+			for _, d := range ends(to.A, to.B, to.Frac, false) {
+				path, c, _, _ := Dijkstra(g, s.node, d.node, DistanceCost)
+				if len(path) == 0 {
+					continue
+				}
+				if total := s.cost + c + d.cost; want < 0 || total < want {
+					want = total
+				}
+			}
+		}
+
+		path, got, _, err := Dijkstra(vg, -1, -2, DistanceCost)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want >= 0 && len(path) == 0 {
+			t.Fatalf("%d: no route between the virtual nodes", k)
+		}
+		if !sameRoad && got != want || sameRoad && want >= 0 && got > want {
+			t.Fatalf("%d: %d, want %d", k, got, want)
+		}
+
+		// searches keyed by edge see the same graph
+		_, edgeCost, _, err := EdgeDijkstra(vg, -1, -2, DistanceCost, NewTurns(nil, 0))
+		if err != nil || edgeCost != got {
+			t.Fatalf("%d: edge dijkstra %d, dijkstra %d (%v)", k, edgeCost, got, err)
+		}
+		if _, bidi, _, _ := BidirectionalDijkstra(vg, -1, -2, DistanceCost); bidi != got {
+			t.Fatalf("%d: bidirectional %d, dijkstra %d", k, bidi, got)
+		}
+	}
+}
+
+func TestSourceEdge(t *testing.T) {
+	g := testGrid(3, 1)
+	e := g.edges()[0]
+	vg, err := WithVirtual(g, Virtual{ID: -1, A: e.Src, B: e.Dst, Frac: 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	in, _ := vg.InNeighbors(-1)
+	out, _ := vg.Neighbors(-1)
+	for _, p := range append(in, out...) {
+		if id := SourceEdge(p.ID); id != e.ID && !reverses(g, id, e) {
+			t.Fatalf("piece %d maps to edge %d, not %d", p.ID, id, e.ID)
+		}
+	}
+	if SourceEdge(e.ID) != e.ID {
+		t.Fatal("SourceEdge changed a real edge id")
+	}
+}
+
+// reverses reports whether edge id of g runs the other way along e's road.
+func reverses(g *testGraph, id int64, e model.Edge) bool {
+	for _, r := range g.out[e.Dst] {
+		if r.ID == id && r.Dst == e.Src {
+			return true
+		}
+	}
+	return false
+}
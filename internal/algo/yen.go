@@ -0,0 +1,94 @@
+package algo
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/atharv3903/graphion/internal/model"
+)
+
+// KShortestPaths returns up to k loopless src-dst paths in increasing cost
+// order (Yen's algorithm). Every spur search is a Dijkstra over g with the
+// root path's nodes and the already used continuations hidden. explored is
+// summed over all searches.
+func KShortestPaths(g Graph, src, dst int64, k int, cost func(int, int) int) (paths []Result, explored int, err error) {
+	if k <= 0 {
+		return nil, 0, nil
+	}
+
+	path, total, n, err := Dijkstra(g, src, dst, cost)
+	explored += n
+	if err != nil || len(path) == 0 {
+		return nil, explored, err
+	}
+	paths = []Result{{Path: path, Total: total, Explored: n}}
+
+	var candidates []Result
+	seen := map[string]bool{pathKey(path): true}
+
+	for len(paths) < k {
+		prev := paths[len(paths)-1].Path
+		costs, err := prefixCosts(g, prev, cost)
+		if err != nil {
+			return nil, explored, err
+		}
+
+		for i := 0; i+1 < len(prev); i++ {
+			root := prev[:i+1]
+			spur := prev[i]
+
+			hiddenNodes := map[int64]bool{}
+			for _, v := range root[:i] {
+				hiddenNodes[v] = true
+			}
+			hiddenEdges := map[[2]int64]bool{}
+			for _, p := range paths {
+				if len(p.Path) > i+1 && slices.Equal(p.Path[:i+1], root) {
+					hiddenEdges[[2]int64{p.Path[i], p.Path[i+1]}] = true
+				}
+			}
+
+			view := FilterGraph(g, func(e model.Edge) bool {
+				return !hiddenNodes[e.Src] && !hiddenNodes[e.Dst] && !hiddenEdges[[2]int64{e.Src, e.Dst}]
+			})
+			spurPath, spurCost, n, err := Dijkstra(view, spur, dst, cost)
+			explored += n
+			if err != nil {
+				return nil, explored, err
+			}
+			if len(spurPath) == 0 {
+				continue
+			}
+
+			cand := append(slices.Clone(root[:i]), spurPath...)
+			if key := pathKey(cand); !seen[key] {
+				seen[key] = true
+				candidates = append(candidates, Result{Path: cand, Total: costs[i] + spurCost, Explored: n})
+			}
+		}
+
+		if len(candidates) == 0 {
+			break
+		}
+		best := 0
+		for i, c := range candidates {
+			if c.Total < candidates[best].Total {
+				best = i
+			}
+		}
+		paths = append(paths, candidates[best])
+		candidates = slices.Delete(candidates, best, best+1)
+	}
+
+	return paths, explored, nil
+}
+
+func pathKey(path []int64) string {
+	var b strings.Builder
+	for _, v := range path {
+		b.WriteString(strconv.FormatInt(v, 10))
+		b.WriteByte(',')
+	}
+	return b.String()
+}
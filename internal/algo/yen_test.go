@@ -0,0 +1,51 @@
+package algo
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestKShortestPathsMatchEnumeration(t *testing.T) {
+	g := testGrid(4, 7)
+	src, dst := g.ids[0], g.ids[len(g.ids)-1]
+
+	// every simple path, by depth-first search
+	var all []int
+	seen := map[int64]bool{src: true}
+	var walk func(u int64, c int)
+	walk = func(u int64, c int) {
+		if u == dst {
+			all = append(all, c)
+			return
+		}
+		for _, e := range g.out[u] {
+			if !seen[e.Dst] {
+				seen[e.Dst] = true
+				walk(e.Dst, c+TravelTimeCost(e.DistM, e.Speed))
+				delete(seen, e.Dst)
+			}
+		}
+	}
+	walk(src, 0)
+	slices.Sort(all)
+
+	paths, _, err := KShortestPaths(g, src, dst, 15, TravelTimeCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != min(15, len(all)) {
+		t.Fatalf("%d paths, want %d", len(paths), min(15, len(all)))
+	}
+	for i, p := range paths {
+		visited := map[int64]bool{}
+		for _, n := range p.Path {
+			if visited[n] {
+				t.Fatalf("path %d loops: %v", i, p.Path)
+			}
+			visited[n] = true
+		}
+		if p.Total != all[i] || pathCost(t, g, p.Path, TravelTimeCost) != p.Total {
+			t.Fatalf("path %d costs %d, want %d", i, p.Total, all[i])
+		}
+	}
+}
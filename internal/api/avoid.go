@@ -0,0 +1,91 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/atharv3903/graphion/internal/algo"
+	"github.com/atharv3903/graphion/internal/model"
+)
+
+// maxAvoidAreas caps the polygons and boxes one request may avoid.
+const maxAvoidAreas = 20
+
+// parseAvoid reads a /route query's exclusions:
+//
+//	avoid_edges=id,id,...
+//	avoid_classes=motorway,trunk,...
+//	avoid_bbox=minLat,minLon,maxLat,maxLon  (repeatable)
+//	avoid_polygon=lat,lon;lat,lon;lat,lon;...  (repeatable)
+func parseAvoid(q url.Values) (algo.Avoid, error) {
+	var a algo.Avoid
+
+	if s := q.Get("avoid_edges"); s != "" {
+		a.Edges = map[int64]bool{}
+		for _, f := range strings.Split(s, ",") {
+			id, err := strconv.ParseInt(strings.TrimSpace(f), 10, 64)
+			if err != nil {
+				return algo.Avoid{}, fmt.Errorf("bad avoid_edges id %q", f)
+			}
+			a.Edges[id] = true
+		}
+	}
+
+	if s := q.Get("avoid_classes"); s != "" {
+		a.Classes = map[string]bool{}
+		for _, f := range strings.Split(s, ",") {
+			a.Classes[strings.TrimSpace(f)] = true
+		}
+	}
+
+	for _, s := range q["avoid_bbox"] {
+		v, err := parseFloats(s, ",")
+		if err != nil || len(v) != 4 || v[0] > v[2] || v[1] > v[3] {
+			return algo.Avoid{}, fmt.Errorf("avoid_bbox must be minLat,minLon,maxLat,maxLon, got %q", s)
+		}
+		a.Areas = append(a.Areas, algo.BBox(v[0], v[1], v[2], v[3]))
+	}
+
+	for _, s := range q["avoid_polygon"] {
+		var ring []model.Coord
+		for _, pt := range strings.Split(s, ";") {
+			v, err := parseFloats(pt, ",")
+			if err != nil || len(v) != 2 {
+				return algo.Avoid{}, fmt.Errorf("bad avoid_polygon point %q", pt)
+			}
+			ring = append(ring, model.Coord{Lat: v[0], Lon: v[1]})
+		}
+		if len(ring) < 3 {
+			return algo.Avoid{}, fmt.Errorf("avoid_polygon needs at least 3 points")
+		}
+		a.Areas = append(a.Areas, ring)
+	}
+
+	if len(a.Areas) > maxAvoidAreas {
+		return algo.Avoid{}, fmt.Errorf("at most %d avoid areas", maxAvoidAreas)
+	}
+	return a, nil
+}
+
+func parseFloats(s, sep string) ([]float64, error) {
+	var out []float64
+	for _, f := range strings.Split(s, sep) {
+		x, err := strconv.ParseFloat(strings.TrimSpace(f), 64)
+		if err != nil {
+			return nil, err
+		}
+		if !finite(x) {
+			return nil, fmt.Errorf("%q is not a finite number", f)
+		}
+		out = append(out, x)
+	}
+	return out, nil
+}
+
+// finite reports whether x is neither NaN nor infinite. ParseFloat accepts
+// both, and they slip through range checks since every comparison with NaN
+// is false.
+func finite(x float64) bool { return !math.IsNaN(x) && !math.IsInf(x, 0) }
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/atharv3903/graphion/internal/algo"
+	"github.com/atharv3903/graphion/internal/model"
+)
+
+const (
+	maxIsochroneBudgets = 10
+	// defaultIsochroneCell is the grid size in meters the polygons are
+	// drawn on; smaller cells follow the roads closer but leave more holes.
+	defaultIsochroneCell = 250
+)
+
+// handleIsochrone serves /isochrone?src=&budget=&metric=&profile=&cell=. budget is
+// one or more comma separated costs in the metric's unit. The answer is a
+// GeoJSON FeatureCollection with one MultiPolygon per budget, largest
+// first so smaller areas are drawn on top, each listing the nodes it
+// reaches.
+func (s *Server) handleIsochrone(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	src, _ := strconv.ParseInt(q.Get("src"), 10, 64)
+
+	var budgets []int
+	for _, bs := range strings.Split(q.Get("budget"), ",") {
+		b, err := strconv.Atoi(strings.TrimSpace(bs))
+		if err != nil || b < 0 {
+			http.Error(w, "budget must be a list of non-negative integers", 400)
+			return
+		}
+		budgets = append(budgets, b)
+	}
+	if len(budgets) > maxIsochroneBudgets {
+		http.Error(w, "at most "+strconv.Itoa(maxIsochroneBudgets)+" budgets", 400)
+		return
+	}
+	slices.Sort(budgets)
+	budgets = slices.Compact(budgets)
+
+	cellM := float64(defaultIsochroneCell)
+	if cs := q.Get("cell"); cs != "" {
+		c, err := strconv.ParseFloat(cs, 64)
+		if err != nil || !finite(c) || c <= 0 {
+			http.Error(w, "cell must be a positive number of meters", 400)
+			return
+		}
+		cellM = c
+	}
+
+	m, err := algo.ParseMetric(q.Get("metric"))
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	p, err := algo.ParseProfile(q.Get("profile"))
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	lim, ctx, cancel := s.requestLimits(r)
+	defer cancel()
+
+	g, _ := s.routingGraph(ctx, p)
+
+	// one search to the largest budget answers all of them
+	reached, _, err := algo.Reachable(lim.Graph(g), src, budgets[len(budgets)-1], m.Cost)
+	if err != nil {
+		routeError(w, err, lim)
+		return
+	}
+
+	fc := model.FeatureCollection{Type: "FeatureCollection", Features: []model.Feature{}}
+	for i := len(budgets) - 1; i >= 0; i-- {
+		var nodes []int64
+		var points []model.Coord
+		for n, c := range reached {
+			if c > budgets[i] {
+				continue
+			}
+			nodes = append(nodes, n)
+			coord, ok, err := g.Coord(n)
+			if err != nil {
+				http.Error(w, err.Error(), 500)
+				return
+			}
+			if ok {
+				points = append(points, coord)
+			}
+		}
+		slices.Sort(nodes)
+
+		fc.Features = append(fc.Features, model.Feature{
+			Type:     "Feature",
+			Geometry: multiPolygon(algo.GridContour(points, cellM)),
+			Properties: map[string]any{
+				"budget": budgets[i],
+				"metric": m.Name,
+				"nodes":  nodes,
+			},
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/geo+json")
+	json.NewEncoder(w).Encode(fc)
+}
+
+func multiPolygon(polys [][][]model.Coord) model.Geometry {
+	coords := make([][][][2]float64, len(polys))
+	for i, rings := range polys {
+		coords[i] = make([][][2]float64, len(rings))
+		for j, ring := range rings {
+			for _, c := range ring {
+				coords[i][j] = append(coords[i][j], [2]float64{c.Lon, c.Lat})
+			}
+		}
+	}
+	return model.Geometry{Type: "MultiPolygon", Coordinates: coords}
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/atharv3903/graphion/internal/algo"
+	"github.com/atharv3903/graphion/internal/cache"
+	"github.com/atharv3903/graphion/internal/model"
+)
+
+// maxK caps /routes/k; every extra path costs up to one search per node of
+// the previous one.
+const maxK = 10
+
+// handleKRoutes serves /routes/k?src=&dst=&k=&metric=&profile=, the k cheapest
+// loopless paths by Yen's algorithm.
+func (s *Server) handleKRoutes(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	src, _ := strconv.ParseInt(q.Get("src"), 10, 64)
+	dst, _ := strconv.ParseInt(q.Get("dst"), 10, 64)
+
+	k := 3
+	if ks := q.Get("k"); ks != "" {
+		var err error
+		k, err = strconv.Atoi(ks)
+		if err != nil || k < 1 || k > maxK {
+			http.Error(w, "k must be between 1 and "+strconv.Itoa(maxK), 400)
+			return
+		}
+	}
+	m, err := algo.ParseMetric(q.Get("metric"))
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	p, err := algo.ParseProfile(q.Get("profile"))
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	lim, ctx, cancel := s.requestLimits(r)
+	defer cancel()
+
+	g, epoch := s.routingGraph(ctx, p)
+
+	key := cache.RouteKey{
+		Src:     src,
+		Dst:     dst,
+		Algo:    "yen",
+		Metric:  m.Name,
+		Profile: p.Name,
+		Epoch:   epoch,
+		K:       k,
+	}
+
+	if v, ok := s.RC.GetMany(key); ok {
+		v.CacheHit = true
+		json.NewEncoder(w).Encode(v)
+		return
+	}
+
+	paths, explored, err := algo.KShortestPaths(lim.Graph(g), src, dst, k, m.Cost)
+	if err != nil {
+		routeError(w, err, lim)
+		return
+	}
+
+	resp := model.KRoutesResponse{
+		Routes:        []model.RouteResponse{},
+		Metric:        m.Name,
+		ExploredNodes: explored,
+	}
+	for _, p := range paths {
+		rr, err := routeResponse(g, m, p)
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		resp.Routes = append(resp.Routes, rr)
+	}
+	if len(paths) > 0 {
+		s.RC.PutMany(key, resp)
+	}
+
+	json.NewEncoder(w).Encode(resp)
+}
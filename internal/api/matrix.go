@@ -0,0 +1,161 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"github.com/atharv3903/graphion/internal/algo"
+	"github.com/atharv3903/graphion/internal/model"
+)
+
+// Limits on one POST /matrix: each source is one search, and the response
+// holds every cell.
+const (
+	maxMatrixSources = 500
+	maxMatrixCells   = 250000
+)
+
+// handleMatrix serves POST /matrix, the cost from every source to every
+// target.
+func (s *Server) handleMatrix(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST only", 405)
+		return
+	}
+
+	var req model.MatrixRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	if len(req.Sources) == 0 || len(req.Targets) == 0 {
+		http.Error(w, "sources and targets must not be empty", 400)
+		return
+	}
+	if len(req.Sources) > maxMatrixSources || len(req.Sources)*len(req.Targets) > maxMatrixCells {
+		http.Error(w, fmt.Sprintf("matrix too large: at most %d sources and %d cells", maxMatrixSources, maxMatrixCells), 400)
+		return
+	}
+	m, err := algo.ParseMetric(req.Metric)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	p, err := algo.ParseProfile(req.Profile)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	lim, ctx, cancel := s.requestLimits(r)
+	defer cancel()
+
+	g, _ := s.routingGraph(ctx, p)
+
+	costs, explored, err := s.costMatrix(lim.Graph(g), nil, req.Sources, req.Targets, m)
+	if err != nil {
+		routeError(w, err, lim)
+		return
+	}
+
+	resp := model.MatrixResponse{
+		Costs:         make([][]*int, len(costs)),
+		Metric:        m.Name,
+		ExploredNodes: explored,
+	}
+	for i, row := range costs {
+		resp.Costs[i] = make([]*int, len(row))
+		for j, c := range row {
+			if c != algo.Unreachable {
+				resp.Costs[i][j] = &row[j]
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// costMatrix runs one OneToMany search per source and returns costs[i][j]
+// from sources[i] to targets[j]. With a non-nil rt each row is instead
+// routed pair by pair with that engine, for engines whose costs may differ
+// from a search of g. Searches of all requests share the s.MatrixWorkers
+// slots of matrixSlots; after a search fails no further sources are
+// started.
+func (s *Server) costMatrix(g algo.Graph, rt algo.Router, sources, targets []int64, m algo.Metric) ([][]int, int, error) {
+	costs := make([][]int, len(sources))
+	explored := make([]int, len(sources))
+	errs := make([]error, len(sources))
+
+	slots := s.matrixSlots()
+	workers := min(cap(slots), len(sources))
+	rows := make(chan int)
+	var failed atomic.Bool
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range rows {
+				slots <- struct{}{}
+				if rt == nil {
+					costs[i], explored[i], errs[i] = algo.OneToMany(g, sources[i], targets, m.Cost)
+				} else {
+					costs[i], explored[i], errs[i] = s.routeRow(g, rt, sources[i], targets, m)
+				}
+				<-slots
+				if errs[i] != nil {
+					failed.Store(true)
+				}
+			}
+		}()
+	}
+	for i := range sources {
+		if failed.Load() {
+			break
+		}
+		rows <- i
+	}
+	close(rows)
+	wg.Wait()
+
+	total := 0
+	for i := range sources {
+		if errs[i] != nil {
+			return nil, 0, errs[i]
+		}
+		total += explored[i]
+	}
+	return costs, total, nil
+}
+
+// routeRow is one costMatrix row routed by rt, one query per target.
+func (s *Server) routeRow(g algo.Graph, rt algo.Router, src int64, targets []int64, m algo.Metric) ([]int, int, error) {
+	row := make([]int, len(targets))
+	explored := 0
+	for j, dst := range targets {
+		res, err := rt.Route(g, algo.Query{Src: src, Dst: dst, Metric: m, MaxSpeed: s.topSpeed(), Stretch: s.stretch()})
+		if err != nil {
+			return nil, 0, err
+		}
+		explored += res.Explored
+		row[j] = res.Total
+		if len(res.Path) == 0 {
+			row[j] = algo.Unreachable
+		}
+	}
+	return row, explored, nil
+}
+
+// matrixSlots returns the server-wide semaphore of matrix searches,
+// sized by MatrixWorkers on first use.
+func (s *Server) matrixSlots() chan struct{} {
+	s.matrixOnce.Do(func() {
+		s.matrixSem = make(chan struct{}, max(s.MatrixWorkers, 1))
+	})
+	return s.matrixSem
+}
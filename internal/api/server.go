@@ -1,15 +1,31 @@
 package api
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
 	"net/http"
+	"net/url"
+	"os"
+	"runtime"
+	"slices"
 	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/atharv3903/graphion/internal/algo"
 	"github.com/atharv3903/graphion/internal/cache"
+	"github.com/atharv3903/graphion/internal/ch"
+	"github.com/atharv3903/graphion/internal/crp"
 	"github.com/atharv3903/graphion/internal/db"
+	"github.com/atharv3903/graphion/internal/graph"
 	"github.com/atharv3903/graphion/internal/model"
+	"github.com/atharv3903/graphion/internal/spatial"
 )
 
 type Server struct {
@@ -19,6 +35,78 @@ type Server struct {
 	RC    *cache.RouteCache
 	AdjCap int
 
+	// engines selectable through /route?algo=
+	Routers *algo.Registry
+
+	// in-memory graph versions, nil unless EnableInMemory was called
+	Snaps *graph.Snapshots
+	// profile name -> profiledCSR of the latest snapshot
+	profiled   map[string]profiledCSR
+	profiledMu sync.Mutex
+	// serialises MySQL write + snapshot publish in memory mode
+	updateMu sync.Mutex
+
+	// contraction hierarchy, nil unless EnableCH was called
+	CH *ch.Manager
+
+	// CRP overlay, nil unless EnableCRP was called
+	CRP *crp.Manager
+
+	// ALT landmark tables, nil until EnableALT has loaded or built them
+	ALT         atomic.Pointer[algo.Landmarks]
+	altPath     string
+	altCount    int
+	altStrategy string
+	altMetric   algo.Metric
+	// altGen is bumped by every rebuildLandmarks; a rebuild whose edges
+	// predate the latest bump is discarded
+	altGen     atomic.Uint64
+	altMu      sync.Mutex
+	altRunning bool
+
+	// limits on /route?alternatives=N routes
+	Alternatives algo.AltOptions
+
+	// matrix searches running at once, across all requests that need
+	// them
+	MatrixWorkers int
+	matrixSem     chan struct{}
+	matrixOnce    sync.Once
+
+	// speed profiles for /route?depart_at=, nil unless EnableTraffic was
+	// called
+	Traffic *algo.Traffic
+
+	// node and segment indexes and snap candidates for /route?from=&to=,
+	// built on first use and refreshed in the background once the graph
+	// version they were built from is out of date
+	grid              *spatial.Grid
+	gridVersion       uint64
+	gridRefreshing    bool
+	segGrid           *spatial.SegmentGrid
+	segGridVersion    uint64
+	segGridRefreshing bool
+	gridMu            sync.Mutex
+	snapSets          map[string]snapSet
+	snapRefreshing    map[string]bool
+	snapMu            sync.Mutex
+
+	// bounds on the searches of one route, k routes, isochrone, matrix or
+	// trip request, 0 for none
+	MaxExplored   int
+	SearchTimeout time.Duration
+
+	// POST /vrp jobs by id
+	vrp *vrpJobs
+
+	// upper bound on edge speeds for the A* time heuristics, read from the
+	// store once maxSpeedKnown is unset
+	maxSpeed      atomic.Int64
+	maxSpeedKnown atomic.Bool
+	// and on edge lengths over straight-line distances, as float64 bits;
+	// lengths never change once imported
+	minStretch   atomic.Uint64
+	stretchKnown atomic.Bool
 }
 
 func New(conn *sql.DB) *Server {
@@ -27,28 +115,311 @@ func New(conn *sql.DB) *Server {
 		Store: db.Store{DB: conn},
 		RC:    cache.NewRouteCache(),
 		AdjCap:  128, // 2048, // or from env
+		Routers: algo.NewRegistry(),
+
+		Alternatives:  algo.DefaultAltOptions,
+		MatrixWorkers: runtime.NumCPU(),
+
+		vrp: newVRPJobs(),
 	}
 
 	s.GCtx = algo.GraphCtx{
-		Store: s.Store,
-		Adj:   cache.NewAdjCacheWithCap(s.AdjCap),
+		Store:  s.Store,
+		Adj:    cache.NewAdjCacheWithCap(s.AdjCap),
+		RevAdj: cache.NewAdjCacheWithCap(s.AdjCap),
+		Coords: cache.NewCoordCache(),
 	}
 
 	s.routes()
 	return s
 }
 
+// EnableInMemory loads the whole graph into a CSR and routes against it
+// instead of querying MySQL per node. Road updates are written to MySQL and
+// then published as a new graph snapshot.
+func (s *Server) EnableInMemory() error {
+	start := time.Now()
+	g, err := graph.Load(s.Store)
+	if err != nil {
+		return err
+	}
+	s.Snaps = graph.NewSnapshots(g)
+	log.Printf("in-memory graph: %d nodes, %d edges in %v", g.Len(), g.Edges(), time.Since(start))
+	return nil
+}
+
+// routingGraph returns what a request for profile p searches against, plus
+// the epoch its route cache entries belong to. In memory mode that is p's
+// view of the current snapshot and the snapshot's version, so a request
+// never mixes two graph versions; otherwise p's view of the MySQL-backed
+// caches and the route cache epoch, with reads abandoned once ctx is done.
+func (s *Server) routingGraph(ctx context.Context, p algo.Profile) (algo.Graph, uint64) {
+	if s.Snaps != nil {
+		g := s.Snaps.Load()
+		return s.profiledSnapshot(g, p), g.Version()
+	}
+	return p.Graph(s.GCtx.WithContext(ctx)), s.RC.Epoch()
+}
+
+type profiledCSR struct {
+	base uint64
+	g    *graph.CSR
+}
+
+// profiledSnapshot returns a CSR holding only p's edges of g, built once
+// per snapshot version so that searches keep the dense fast path. Every
+// caller gets the same CSR for a version, which the CRP overlay relies on
+// to recognise the graph it was customized for. Only the newest version
+// is kept: a request still holding an older snapshot gets a CSR of its
+// own rather than evicting the current one.
+func (s *Server) profiledSnapshot(g *graph.CSR, p algo.Profile) *graph.CSR {
+	s.profiledMu.Lock()
+	defer s.profiledMu.Unlock()
+	v, ok := s.profiled[p.Name]
+	if ok && v.base == g.Version() {
+		return v.g
+	}
+	pg := g.WithEdges(p.Edges)
+	if ok && v.base > g.Version() {
+		return pg
+	}
+	if s.profiled == nil {
+		s.profiled = map[string]profiledCSR{}
+	}
+	s.profiled[p.Name] = profiledCSR{base: g.Version(), g: pg}
+	return pg
+}
+
+// publishEdgeUpdate mirrors an applied /road/update into a new snapshot.
+func (s *Server) publishEdgeUpdate(edgeID int64, closed *bool, speed *int) error {
+	var reopen *model.Edge
+	if closed != nil && !*closed {
+		e, err := s.Store.Edge(edgeID)
+		if err != nil {
+			return err
+		}
+		reopen = &e
+	}
+
+	s.Snaps.Update(func(edges []model.Edge) []model.Edge {
+		if closed != nil {
+			// drop and re-add, so reopening an open edge can't duplicate it
+			edges = slices.DeleteFunc(edges, func(e model.Edge) bool { return e.ID == edgeID })
+			if reopen != nil {
+				edges = append(edges, *reopen)
+			}
+		}
+		if speed != nil {
+			for i := range edges {
+				if edges[i].ID == edgeID {
+					edges[i].Speed = *speed
+				}
+			}
+		}
+		return edges
+	})
+	return nil
+}
+
+// EnableTurns serves algo=turns, an edge-based search that obeys the
+// stored turn restrictions and charges uturnSeconds for U-turns (negative
+// forbids them).
+func (s *Server) EnableTurns(uturnSeconds float64) error {
+	rs, err := s.Store.TurnRestrictions()
+	if err != nil {
+		return err
+	}
+	t := algo.NewTurns(rs, uturnSeconds)
+	s.Routers.Register(algo.TurnRouter{Turns: t})
+	log.Printf("turns: %d restrictions", t.Len())
+	return nil
+}
+
+// EnableCH serves algo=ch for metric m from a contraction hierarchy. The
+// hierarchy is loaded or built in the background; until it is ready, and
+// while it is being rebuilt after an update that changed m, algo=ch
+// requests are answered by plain Dijkstra.
+func (s *Server) EnableCH(m algo.Metric) {
+	s.CH = ch.NewManager(s.Store, m)
+	s.CH.Start()
+	s.Routers.Register(s.CH)
+}
+
+// EnableCRP serves algo=crp for metric m from a multilevel overlay of the
+// in-memory graph, so it needs EnableInMemory first. The partition and the
+// first customization run in the background; road updates afterwards only
+// re-customize the cells holding the changed edge.
+func (s *Server) EnableCRP(m algo.Metric) error {
+	if s.Snaps == nil {
+		return fmt.Errorf("crp needs the in-memory graph")
+	}
+	s.CRP = crp.NewManager(m)
+	s.CRP.Graph = func() *graph.CSR { return s.profiledSnapshot(s.Snaps.Load(), algo.DefaultProfile) }
+	s.CRP.Start(s.profiledSnapshot(s.Snaps.Load(), algo.DefaultProfile))
+	s.Routers.Register(s.CRP)
+	return nil
+}
+
+// EnableALT serves algo=alt for metric m from landmark tables. Tables are
+// read from path if it exists, otherwise count landmarks are
+// selected with strategy and the result is written to path. Until the
+// tables are ready, and while they are recomputed after an update made some
+// edge cheaper, algo=alt requests are answered by plain Dijkstra.
+func (s *Server) EnableALT(path string, count int, strategy string, m algo.Metric) {
+	s.altPath, s.altCount, s.altStrategy, s.altMetric = path, count, strategy, m
+	s.Routers.Register(algo.ALTRouter{Metric: m, Tables: s.ALT.Load})
+
+	lm, err := algo.LoadLandmarks(path)
+	switch {
+	case err == nil && lm.Metric == m.Name:
+		edges, err := s.Store.AllEdges()
+		if err != nil {
+			log.Printf("alt: loading edges: %v", err)
+			break
+		}
+		if algo.EdgeFingerprint(algo.DefaultProfile.Edges(edges), m) != lm.Edges {
+			log.Printf("alt: %s was built from other edges, rebuilding", path)
+			break
+		}
+		s.ALT.Store(lm)
+		log.Printf("alt: loaded %d landmarks from %s", len(lm.Nodes), path)
+		return
+	case err == nil:
+		log.Printf("alt: %s holds %s tables, rebuilding for %s", path, lm.Metric, m.Name)
+	case !os.IsNotExist(err):
+		log.Printf("alt: %s unreadable, rebuilding: %v", path, err)
+	}
+	s.rebuildLandmarks()
+}
+
+// rebuildLandmarks recomputes the landmark tables in the background. Calls
+// made while a rebuild is running make it run again once it is done, since
+// the edges it read may predate them; its tables are then discarded.
+func (s *Server) rebuildLandmarks() {
+	s.altGen.Add(1)
+
+	s.altMu.Lock()
+	defer s.altMu.Unlock()
+	if s.altRunning {
+		return
+	}
+	s.altRunning = true
+	go func() {
+		for {
+			gen := s.altGen.Load()
+			if err := s.buildLandmarks(gen); err != nil {
+				log.Printf("alt: %v", err)
+			}
+
+			s.altMu.Lock()
+			if s.altGen.Load() == gen {
+				s.altRunning = false
+				s.altMu.Unlock()
+				return
+			}
+			s.altMu.Unlock()
+		}
+	}()
+}
+
+// buildLandmarks computes and saves the tables, unless rebuildLandmarks
+// was called again after generation gen read its edges.
+func (s *Server) buildLandmarks(gen uint64) error {
+	edges, err := s.Store.AllEdges()
+	if err != nil {
+		return fmt.Errorf("loading edges: %w", err)
+	}
+	edges = algo.DefaultProfile.Edges(edges)
+	lm, err := algo.BuildLandmarks(s.altMetric, edges, s.altCount, s.altStrategy)
+	if err != nil {
+		return err
+	}
+	s.ALT.Store(lm)
+	// checked after the store: handleUpdate bumps the generation before
+	// marking the tables it sees stale, so one of the two catches them
+	if s.altGen.Load() != gen {
+		lm.MarkStale()
+		log.Printf("alt: edges changed during the build, discarding it")
+		return nil
+	}
+	log.Printf("alt: built %d landmarks", len(lm.Nodes))
+
+	if err := algo.SaveLandmarks(s.altPath, lm); err != nil {
+		return fmt.Errorf("saving %s: %w", s.altPath, err)
+	}
+	return nil
+}
+
+// topSpeed returns an upper bound on edge speeds, asking the store the
+// first time, even if that finds no edges. Speed updates and reopened
+// edges raise it through raiseTopSpeed.
+func (s *Server) topSpeed() int {
+	if s.maxSpeedKnown.Load() {
+		return int(s.maxSpeed.Load())
+	}
+	v, err := s.Store.MaxSpeed()
+	if err != nil {
+		log.Printf("max speed: %v", err)
+		return 0
+	}
+	s.raiseTopSpeed(v)
+	s.maxSpeedKnown.Store(true)
+	return int(s.maxSpeed.Load())
+}
+
+// stretch returns the smallest ratio of an edge's length to the straight
+// line between its ends, reading every node and edge from the store the
+// first time that succeeds. It is 0, which turns the distance heuristics
+// off, until then.
+func (s *Server) stretch() float64 {
+	if s.stretchKnown.Load() {
+		return math.Float64frombits(s.minStretch.Load())
+	}
+	nodes, err := s.Store.AllNodes()
+	if err != nil {
+		log.Printf("min stretch: %v", err)
+		return 0
+	}
+	edges, err := s.Store.Lengths()
+	if err != nil {
+		log.Printf("min stretch: %v", err)
+		return 0
+	}
+	v := algo.MinStretch(nodes, edges)
+	s.minStretch.Store(math.Float64bits(v))
+	s.stretchKnown.Store(true)
+	return v
+}
+
+func (s *Server) raiseTopSpeed(v int) {
+	for {
+		cur := s.maxSpeed.Load()
+		if int64(v) <= cur || s.maxSpeed.CompareAndSwap(cur, int64(v)) {
+			return
+		}
+	}
+}
+
 func (s *Server) routes() {
 	s.Mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
 		w.Write([]byte("ok"))
 	})
 
 	s.Mux.HandleFunc("/route", s.handleRoute)
+	s.Mux.HandleFunc("/routes/k", s.handleKRoutes)
+	s.Mux.HandleFunc("/matrix", s.handleMatrix)
+	s.Mux.HandleFunc("/isochrone", s.handleIsochrone)
+	s.Mux.HandleFunc("/trip", s.handleTrip)
+	s.Mux.HandleFunc("/vrp", s.handleVRPSubmit)
+	s.Mux.HandleFunc("/vrp/", s.handleVRPStatus)
+	s.Mux.HandleFunc("/algos", s.handleAlgos)
 	s.Mux.HandleFunc("/road/update", s.handleUpdate)
 
 	s.Mux.HandleFunc("/debug/clear_cache", func(w http.ResponseWriter, r *http.Request) {
 		// s.GCtx.Adj = cache.NewAdjCache()
 		s.GCtx.Adj = cache.NewAdjCacheWithCap(s.AdjCap)
+		s.GCtx.RevAdj = cache.NewAdjCacheWithCap(s.AdjCap)
+		s.GCtx.Coords = cache.NewCoordCache()
 
 		s.RC = cache.NewRouteCache()
 		w.Write([]byte("cleared"))
@@ -73,42 +444,463 @@ func (s *Server) routes() {
 
 }
 
+// maxAlternatives caps /route?alternatives=.
+const maxAlternatives = 5
+
 func (s *Server) handleRoute(w http.ResponseWriter, r *http.Request) {
 	q := r.URL.Query()
 
 	src, _ := strconv.ParseInt(q.Get("src"), 10, 64)
 	dst, _ := strconv.ParseInt(q.Get("dst"), 10, 64)
 
-	key := cache.RouteKey{
-		Src:   src,
-		Dst:   dst,
-		Algo:  "dijkstra",
-		Epoch: s.RC.Epoch(),
+	name := q.Get("algo")
+	if name == "" {
+		name = "dijkstra"
+	}
+	m, err := algo.ParseMetric(q.Get("metric"))
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	p, err := algo.ParseProfile(q.Get("profile"))
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	v, err := parseVehicle(q)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	avoid, err := parseAvoid(q)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	// from and to are coordinates to snap to src and dst, onto the nodes
+	// or onto virtual nodes partway along roads
+	var from, to *model.Snap
+	var virtuals []algo.Virtual
+	if fs, ts := q.Get("from"), q.Get("to"); fs != "" || ts != "" {
+		if fs == "" || ts == "" || q.Get("points") != "" {
+			http.Error(w, "from and to need each other and cannot be combined with points", 400)
+			return
+		}
+		fc, err := parseCoord(fs)
+		if err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+		tc, err := parseCoord(ts)
+		if err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+		mode := q.Get("snap")
+		switch mode {
+		case "":
+			mode = snapProfile
+		case snapRoutable, snapProfile, snapComponent:
+		default:
+			http.Error(w, "snap must be "+snapRoutable+", "+snapProfile+" or "+snapComponent, 400)
+			return
+		}
+		snapTo := q.Get("snap_to")
+		switch snapTo {
+		case "":
+			snapTo = snapToNode
+		case snapToEdge, snapToNode:
+		default:
+			http.Error(w, "snap_to must be "+snapToEdge+" or "+snapToNode, 400)
+			return
+		}
+		for _, sn := range []struct {
+			c   model.Coord
+			dst **model.Snap
+			id  int64
+		}{{fc, &from, fromNode}, {tc, &to, toNode}} {
+			if snapTo == snapToEdge {
+				var vn algo.Virtual
+				*sn.dst, vn, err = s.snapEdge(sn.c, p, mode, sn.id)
+				virtuals = append(virtuals, vn)
+			} else {
+				*sn.dst, err = s.snapNode(sn.c, p, mode)
+			}
+			if err != nil {
+				status := 500
+				if errors.Is(err, errNoSnap) {
+					status = 422
+				}
+				http.Error(w, err.Error(), status)
+				return
+			}
+		}
+		src, dst = fromNode, toNode
+		if snapTo == snapToNode {
+			src, dst = from.Node, to.Node
+		}
+	}
+
+	alternatives := 0
+	if as := q.Get("alternatives"); as != "" {
+		alternatives, err = strconv.Atoi(as)
+		if err != nil || alternatives < 0 || alternatives > maxAlternatives {
+			http.Error(w, "alternatives must be between 0 and "+strconv.Itoa(maxAlternatives), 400)
+			return
+		}
+	}
+
+	// depart_at and arrive_by route on the traffic at that time
+	var at time.Time
+	ds, as := q.Get("depart_at"), q.Get("arrive_by")
+	arrive := as != ""
+	if ds != "" && arrive {
+		http.Error(w, "depart_at and arrive_by cannot be combined", 400)
+		return
+	}
+	if ds != "" || arrive {
+		param, v := "depart_at", ds
+		if arrive {
+			param, v = "arrive_by", as
+		}
+		if at, err = parseTime(v); err != nil {
+			http.Error(w, param+": "+err.Error(), 400)
+			return
+		}
+		switch {
+		case q.Get("metric") == "":
+			m = algo.TravelTime
+		case m.Name != algo.TravelTime.Name:
+			http.Error(w, "depart_at and arrive_by need metric="+algo.TravelTime.Name, 400)
+			return
+		}
+		if name != "dijkstra" && name != "astar" {
+			http.Error(w, "depart_at and arrive_by need algo dijkstra or astar", 400)
+			return
+		}
+		if alternatives > 0 || q.Get("points") != "" {
+			http.Error(w, "depart_at and arrive_by cannot be combined with alternatives or points", 400)
+			return
+		}
+	}
+
+	rt, ok := s.Routers.Lookup(name)
+	if !ok {
+		http.Error(w, "unknown algo "+name, 400)
+		return
+	}
+	if err := checkRouter(rt, m, p); err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	if (v != (model.Vehicle{}) || !avoid.Empty()) && rt.Capabilities().FixedGraph {
+		http.Error(w, name+" cannot apply vehicle restrictions or exclusions", 400)
+		return
+	}
+	if len(virtuals) > 0 && rt.Capabilities().FixedGraph {
+		http.Error(w, name+" cannot start or end partway along a road, use snap_to="+snapToNode, 400)
+		return
+	}
+
+	lim, ctx, cancel := s.requestLimits(r)
+	defer cancel()
+
+	g, epoch := s.routingGraph(ctx, p)
+	if !avoid.Empty() {
+		g = algo.AvoidGraph(g, avoid)
+	}
+	sp := routeSpec{g: g, lim: lim, epoch: epoch, rt: rt, metric: m, profile: p, vehicle: v, avoid: avoid.Hash()}
+	if v != (model.Vehicle{}) {
+		sp.unrestricted = sp.g
+		sp.g = algo.VehicleGraph(g, v)
+	}
+	if len(virtuals) > 0 {
+		if sp.g, err = algo.WithVirtual(sp.g, virtuals...); err != nil {
+			routeError(w, err, lim)
+			return
+		}
+		if sp.unrestricted != nil {
+			if sp.unrestricted, err = algo.WithVirtual(sp.unrestricted, virtuals...); err != nil {
+				routeError(w, err, lim)
+				return
+			}
+		}
+		sp.virtual = true
 	}
 
-	if v, ok := s.RC.Get(key); ok {
-		json.NewEncoder(w).Encode(model.RouteResponse{Path: v, CacheHit: true})
+	if !at.IsZero() {
+		resp, err := s.timedRoute(sp, src, dst, at, arrive, name == "astar")
+		if err != nil {
+			routeError(w, err, lim)
+			return
+		}
+		if from != nil {
+			if err := snapped(&resp, sp.g, from, to); err != nil {
+				routeError(w, err, lim)
+				return
+			}
+		}
+		json.NewEncoder(w).Encode(resp)
 		return
 	}
 
-	cost := func(dist, speed int) int { return dist }
+	if ps := q.Get("points"); ps != "" {
+		if alternatives > 0 {
+			http.Error(w, "alternatives cannot be combined with points", 400)
+			return
+		}
+		points, err := parsePoints(ps)
+		if err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+		resp, err := s.viaRoute(sp, points)
+		if err != nil {
+			routeError(w, err, lim)
+			return
+		}
+		json.NewEncoder(w).Encode(resp)
+		return
+	}
 
-	path, total, explored, err := algo.Dijkstra(s.GCtx, src, dst, cost)
+	resp, err := s.cachedRoute(sp, src, dst, alternatives)
 	if err != nil {
-		http.Error(w, err.Error(), 500)
+		routeError(w, err, lim)
 		return
 	}
+	if from != nil {
+		if err := snapped(&resp, sp.g, from, to); err != nil {
+			routeError(w, err, lim)
+			return
+		}
+	}
+
+	json.NewEncoder(w).Encode(resp)
+}
+
+// requestLimits returns the limits on the searches of one request, which
+// run under the returned context: r's, cut off after SearchTimeout. cancel
+// must be called once the request is done.
+func (s *Server) requestLimits(r *http.Request) (*algo.Limits, context.Context, context.CancelFunc) {
+	ctx, cancel := r.Context(), context.CancelFunc(func() {})
+	if s.SearchTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, s.SearchTimeout)
+	}
+	return algo.NewLimits(ctx, s.MaxExplored), ctx, cancel
+}
+
+// Error codes of a search that was cut short.
+const (
+	errExploreBudget = "explore_budget_exceeded"
+	errDeadline      = "deadline_exceeded"
+	errCanceled      = "canceled"
+)
 
-	if len(path) > 0 {
-		s.RC.Put(key, path)
+// routeError answers a request whose search failed. One stopped by its
+// limits gets a response carrying the error code and the nodes it
+// explored, so clients can tell it apart from there being no route.
+func routeError(w http.ResponseWriter, err error, lim *algo.Limits) {
+	var code string
+	status := 500
+	switch {
+	case errors.Is(err, algo.ErrExploreBudget):
+		code, status = errExploreBudget, 422
+	case errors.Is(err, context.DeadlineExceeded):
+		code, status = errDeadline, 504
+	case errors.Is(err, context.Canceled):
+		code, status = errCanceled, 499
+	default:
+		http.Error(w, err.Error(), status)
+		return
 	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(model.RouteResponse{Error: code, ExploredNodes: lim.Explored()})
+}
 
-	json.NewEncoder(w).Encode(model.RouteResponse{
-		Path:          path,
-		Total:         total,
-		ExploredNodes: explored,
-		CacheHit:      false,
-	})
+// routeSpec is everything besides the endpoints that decides a route: the
+// graph and its epoch, the engine, the metric, the profile, the vehicle's
+// size and what the request avoids.
+type routeSpec struct {
+	g algo.Graph
+	// limits the searches on g count against; reading back the edges of
+	// a path found does not
+	lim     *algo.Limits
+	epoch   uint64
+	rt      algo.Router
+	metric  algo.Metric
+	profile algo.Profile
+	vehicle model.Vehicle
+	avoid   uint64 // algo.Avoid.Hash
+	// the graph before vehicle restrictions, nil without any
+	unrestricted algo.Graph
+	// virtual is set when the endpoints are the virtual nodes of edge
+	// snapping, whose ids every such request shares, so the route is not
+	// cached
+	virtual bool
+}
+
+// search returns g for the request's searches, counted against its limits.
+func (sp routeSpec) search(g algo.Graph) algo.Graph {
+	if sp.lim == nil {
+		return g
+	}
+	return sp.lim.Graph(g)
+}
+
+func (sp routeSpec) key(src, dst int64, k int) cache.RouteKey {
+	return cache.RouteKey{
+		Src:     src,
+		Dst:     dst,
+		Algo:    sp.rt.Name(),
+		Metric:  sp.metric.Name,
+		Profile: sp.profile.Name,
+		Epoch:   sp.epoch,
+		Vehicle: sp.vehicle,
+		Avoid:   sp.avoid,
+		K:       k,
+	}
+}
+
+// checkRouter rejects an engine that can't answer for m and p.
+func checkRouter(rt algo.Router, m algo.Metric, p algo.Profile) error {
+	c := rt.Capabilities()
+	if !c.Supports(m.Name) {
+		return fmt.Errorf("%s does not support metric %s", rt.Name(), m.Name)
+	}
+	if !c.SupportsProfile(p.Name) {
+		return fmt.Errorf("%s does not support profile %s", rt.Name(), p.Name)
+	}
+	return nil
+}
+
+// cachedRoute answers one src-dst query from the route cache, or searches
+// and caches the answer.
+func (s *Server) cachedRoute(sp routeSpec, src, dst int64, alternatives int) (model.RouteResponse, error) {
+	g, m := sp.search(sp.g), sp.metric
+	key := sp.key(src, dst, alternatives)
+
+	if !sp.virtual {
+		if v, ok := s.RC.Get(key); ok {
+			v.CacheHit = true
+			return v, nil
+		}
+	}
+
+	res, err := sp.rt.Route(g, algo.Query{Src: src, Dst: dst, Metric: m, MaxSpeed: s.topSpeed(), Stretch: s.stretch()})
+	if err != nil {
+		return model.RouteResponse{}, err
+	}
+
+	resp, err := routeResponse(sp.g, m, res)
+	if err != nil {
+		return model.RouteResponse{}, err
+	}
+
+	if alternatives > 0 {
+		alts, explored, err := algo.Alternatives(g, res, alternatives, m.Cost, s.Alternatives)
+		if err != nil {
+			return model.RouteResponse{}, err
+		}
+		resp.ExploredNodes += explored
+		for _, a := range alts {
+			ar, err := routeResponse(sp.g, m, a.Result)
+			if err != nil {
+				return model.RouteResponse{}, err
+			}
+			resp.Alternatives = append(resp.Alternatives, model.AlternativeRoute{RouteResponse: ar, Shared: a.Shared})
+		}
+	}
+
+	if len(res.Path) == 0 && sp.unrestricted != nil {
+		if resp.Blocked, err = blockedBy(sp, src, dst); err != nil {
+			return model.RouteResponse{}, err
+		}
+	}
+
+	if len(res.Path) > 0 && !sp.virtual {
+		s.RC.Put(key, resp)
+	}
+	return resp, nil
+}
+
+// blockedBy explains a missing route for a restricted vehicle: it routes
+// without the restrictions and lists the edges on that route the vehicle
+// may not use. It returns nil when there is no route either way.
+func blockedBy(sp routeSpec, src, dst int64) ([]model.BlockedEdge, error) {
+	path, _, _, err := algo.Dijkstra(sp.search(sp.unrestricted), src, dst, sp.metric.Cost)
+	if err != nil || len(path) == 0 {
+		return nil, err
+	}
+	return algo.BlockedEdges(sp.unrestricted, path, sp.vehicle, sp.metric.Cost)
+}
+
+// parseVehicle reads the vehicle's height, weight, width and hazmat flag
+// from a /route query.
+func parseVehicle(q url.Values) (model.Vehicle, error) {
+	var v model.Vehicle
+	for _, f := range []struct {
+		name string
+		dst  *float64
+	}{{"height", &v.Height}, {"weight", &v.Weight}, {"width", &v.Width}} {
+		s := q.Get(f.name)
+		if s == "" {
+			continue
+		}
+		x, err := strconv.ParseFloat(s, 64)
+		if err != nil || !finite(x) || x < 0 {
+			return model.Vehicle{}, fmt.Errorf("%s must be a non-negative number", f.name)
+		}
+		*f.dst = x
+	}
+	if hs := q.Get("hazmat"); hs != "" {
+		h, err := strconv.ParseBool(hs)
+		if err != nil {
+			return model.Vehicle{}, fmt.Errorf("hazmat must be true or false")
+		}
+		v.Hazmat = h
+	}
+	return v, nil
+}
+
+// routeResponse fills in the response for one search result, with meters
+// and seconds summed along the path.
+func routeResponse(g algo.Graph, m algo.Metric, res algo.Result) (model.RouteResponse, error) {
+	resp := model.RouteResponse{
+		Path:          res.Path,
+		Total:         res.Total,
+		Metric:        m.Name,
+		ExploredNodes: res.Explored,
+	}
+	if len(res.Path) == 0 {
+		return resp, nil
+	}
+
+	meters, ms, err := algo.PathTotals(g, res.Path, m.Cost)
+	if err != nil {
+		return model.RouteResponse{}, err
+	}
+	resp.TotalMeters = meters
+	resp.TotalSeconds = float64(ms) / 1000
+	return resp, nil
+}
+
+// handleAlgos lists the engines this server offers and whether their
+// preprocessed data is currently usable.
+func (s *Server) handleAlgos(w http.ResponseWriter, r *http.Request) {
+	type algoInfo struct {
+		Name string `json:"name"`
+		algo.Capabilities
+	}
+
+	var out []algoInfo
+	for _, rt := range s.Routers.List() {
+		out = append(out, algoInfo{Name: rt.Name(), Capabilities: rt.Capabilities()})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(out)
 }
 
 // func (s *Server) handleUpdate(w http.ResponseWriter, r *http.Request) {
@@ -157,17 +949,64 @@ func (s *Server) handleUpdate(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), 400)
 		return
 	}
+	if req.Closed == nil && req.Speed == nil {
+		http.Error(w, "closed or speed_kmph is required", 400)
+		return
+	}
+
+	// Outgoing and incoming edges are cached by tail and head node. The
+	// request carries neither for sure (src_node is optional and unchecked),
+	// so look the edge up to drop the right entries; this also turns away
+	// unknown edges before anything is written.
+	tail, head, err := s.Store.EdgeEndpoints(req.EdgeID)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "unknown edge "+strconv.FormatInt(req.EdgeID, 10), 404)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	// In memory mode the snapshot must see the writes in the order MySQL did
+	if s.Snaps != nil {
+		s.updateMu.Lock()
+		defer s.updateMu.Unlock()
+	}
 
 	// Do the write(s) inside store which now uses SELECT FOR UPDATE
+	reopened := false
 	if req.Closed != nil {
-		if err := s.Store.UpdateEdgeClosed(req.EdgeID, *req.Closed); err != nil {
+		wasClosed, err := s.Store.UpdateEdgeClosed(req.EdgeID, *req.Closed)
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		reopened = wasClosed && !*req.Closed
+	}
+	if reopened {
+		// MaxSpeed only counts open edges, so the bound may not cover it
+		e, err := s.Store.Edge(req.EdgeID)
+		if err != nil {
 			http.Error(w, err.Error(), 500)
 			return
 		}
+		s.raiseTopSpeed(e.Speed)
 	}
 
+	faster := false
 	if req.Speed != nil {
-		if err := s.Store.UpdateEdgeSpeed(req.EdgeID, *req.Speed); err != nil {
+		prev, err := s.Store.UpdateEdgeSpeed(req.EdgeID, *req.Speed)
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		faster = *req.Speed > prev
+		s.raiseTopSpeed(*req.Speed)
+	}
+
+	if s.Snaps != nil {
+		if err := s.publishEdgeUpdate(req.EdgeID, req.Closed, req.Speed); err != nil {
 			http.Error(w, err.Error(), 500)
 			return
 		}
@@ -178,10 +1017,32 @@ func (s *Server) handleUpdate(w http.ResponseWriter, r *http.Request) {
 		s.GCtx.Adj.Invalidate(*req.Src)
 		// FORCE read of that adjacency to cause DB SELECT load (and refill cache)
 		// we ignore the returned edges but this will hit DB via Store.Outgoing
-		_, _ = s.GCtx.Store.Outgoing(*req.Src)
+		_, _ = s.GCtx.Store.Outgoing(r.Context(), *req.Src)
+	}
+
+	s.GCtx.Adj.Invalidate(tail)
+	s.GCtx.RevAdj.Invalidate(head)
+
+	// The overlay follows every snapshot, re-customizing only the cells
+	// that hold the edge
+	if s.CRP != nil {
+		s.CRP.Update(s.profiledSnapshot(s.Snaps.Load(), algo.DefaultProfile), tail, head, req.Closed != nil)
+	}
+
+	if s.CH != nil && (req.Closed != nil || (req.Speed != nil && s.CH.Metric.UsesSpeed)) {
+		s.CH.Invalidate()
+	}
+
+	// Landmark bounds survive edges getting more expensive but not cheaper
+	if s.altMetric.Name != "" && (reopened || (faster && s.altMetric.UsesSpeed)) {
+		s.rebuildLandmarks()
+		if lm := s.ALT.Load(); lm != nil {
+			lm.MarkStale()
+		}
 	}
 
-	// Bump route epoch so cached routes become stale
+	// Bump route epoch so cached routes become stale (memory mode keys
+	// routes by snapshot version instead)
 	s.RC.BumpEpoch()
 
 	json.NewEncoder(w).Encode(map[string]any{"ok": true})
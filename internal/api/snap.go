@@ -0,0 +1,421 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/atharv3903/graphion/internal/algo"
+	"github.com/atharv3903/graphion/internal/model"
+	"github.com/atharv3903/graphion/internal/spatial"
+)
+
+const (
+	// snapCellMeters is the cell size of the node and segment grids.
+	snapCellMeters = 200
+	// maxSnapMeters is how far /route?from=&to= looks for a node or road.
+	maxSnapMeters = 2000
+)
+
+// What /route?snap_to= moves from and to onto; snapToNode unless the
+// request says otherwise, which every engine can route between.
+const (
+	// snapToEdge projects them onto the nearest road, which the route then
+	// starts and ends partway along.
+	snapToEdge = "edge"
+	// snapToNode moves them to the nearest node.
+	snapToNode = "node"
+)
+
+// The virtual nodes edge snapping puts on the roads from and to land on.
+const (
+	fromNode int64 = -1
+	toNode   int64 = -2
+)
+
+// Which nodes and roads /route?snap= lets from and to land on; snapProfile
+// unless the request says otherwise.
+const (
+	// snapRoutable takes any node with an open edge, and roads with one.
+	snapRoutable = "routable"
+	// snapProfile takes nodes and roads with an edge the profile may use.
+	snapProfile = "profile"
+	// snapComponent takes what snapProfile does in the largest strongly
+	// connected part of the profile's graph.
+	snapComponent = "component"
+)
+
+var errNoSnap = errors.New("nothing to snap to within reach")
+
+// snapSet is the nodes and roads one snap mode accepts, as of a graph
+// version. Roads are keyed by their end nodes, lower id first.
+type snapSet struct {
+	version  uint64
+	nodes    map[int64]bool
+	segments map[[2]int64]bool
+}
+
+// graphVersion is the version of the graph requests currently see: the
+// snapshot's in memory mode, the route cache epoch otherwise.
+func (s *Server) graphVersion() uint64 {
+	if s.Snaps != nil {
+		return s.Snaps.Load().Version()
+	}
+	return s.RC.Epoch()
+}
+
+// nodeGrid returns the spatial index of the nodes. Only the first call
+// builds it in line; once the graph has changed the last index keeps being
+// served while a fresh one is built in the background.
+func (s *Server) nodeGrid() (*spatial.Grid, error) {
+	version := s.graphVersion()
+	s.gridMu.Lock()
+	grid := s.grid
+	if grid != nil && s.gridVersion != version && !s.gridRefreshing {
+		s.gridRefreshing = true
+		go func() {
+			next, v, err := s.buildNodeGrid()
+			if err != nil {
+				log.Printf("snap: refreshing node index: %v", err)
+			}
+			s.gridMu.Lock()
+			defer s.gridMu.Unlock()
+			s.gridRefreshing = false
+			if err == nil && v > s.gridVersion {
+				s.grid, s.gridVersion = next, v
+			}
+		}()
+	}
+	s.gridMu.Unlock()
+	if grid != nil {
+		return grid, nil
+	}
+
+	grid, v, err := s.buildNodeGrid()
+	if err != nil {
+		return nil, err
+	}
+	s.gridMu.Lock()
+	defer s.gridMu.Unlock()
+	if s.grid == nil || v > s.gridVersion {
+		s.grid, s.gridVersion = grid, v
+	}
+	return grid, nil
+}
+
+// buildNodeGrid indexes the nodes of the current graph and returns the
+// version it was built from.
+func (s *Server) buildNodeGrid() (*spatial.Grid, uint64, error) {
+	start := time.Now()
+	var nodes []model.Node
+	var version uint64
+	if s.Snaps != nil {
+		g := s.Snaps.Load()
+		version, nodes = g.Version(), g.AllNodes()
+	} else {
+		// read before the nodes, like in buildSnapSet
+		version = s.RC.Epoch()
+		var err error
+		if nodes, err = s.Store.AllNodes(); err != nil {
+			return nil, 0, err
+		}
+	}
+	grid := spatial.NewGrid(nodes, snapCellMeters)
+	log.Printf("snap: indexed %d nodes in %v", grid.Len(), time.Since(start))
+	return grid, version, nil
+}
+
+// segmentGrid returns the spatial index of the roads between nodes, built
+// and refreshed like nodeGrid.
+func (s *Server) segmentGrid() (*spatial.SegmentGrid, error) {
+	version := s.graphVersion()
+	s.gridMu.Lock()
+	grid := s.segGrid
+	if grid != nil && s.segGridVersion != version && !s.segGridRefreshing {
+		s.segGridRefreshing = true
+		go func() {
+			next, v, err := s.buildSegmentGrid()
+			if err != nil {
+				log.Printf("snap: refreshing road index: %v", err)
+			}
+			s.gridMu.Lock()
+			defer s.gridMu.Unlock()
+			s.segGridRefreshing = false
+			if err == nil && v > s.segGridVersion {
+				s.segGrid, s.segGridVersion = next, v
+			}
+		}()
+	}
+	s.gridMu.Unlock()
+	if grid != nil {
+		return grid, nil
+	}
+
+	grid, v, err := s.buildSegmentGrid()
+	if err != nil {
+		return nil, err
+	}
+	s.gridMu.Lock()
+	defer s.gridMu.Unlock()
+	if s.segGrid == nil || v > s.segGridVersion {
+		s.segGrid, s.segGridVersion = grid, v
+	}
+	return grid, nil
+}
+
+// buildSegmentGrid indexes the roads of the current graph and returns the
+// version it was built from. In memory mode those are the snapshot's open
+// roads, and a reopened one is indexed again by the refresh that follows;
+// otherwise closed roads are indexed too, since they may reopen.
+func (s *Server) buildSegmentGrid() (*spatial.SegmentGrid, uint64, error) {
+	start := time.Now()
+	var pairs [][2]int64
+	var nodes []model.Node
+	var version uint64
+	if s.Snaps != nil {
+		g := s.Snaps.Load()
+		version, nodes = g.Version(), g.AllNodes()
+		seen := map[[2]int64]bool{}
+		for _, e := range g.AllEdges() {
+			if k := segmentKey(e.Src, e.Dst); !seen[k] {
+				seen[k] = true
+				pairs = append(pairs, k)
+			}
+		}
+	} else {
+		version = s.RC.Epoch()
+		var err error
+		if pairs, err = s.Store.Segments(); err != nil {
+			return nil, 0, err
+		}
+		if nodes, err = s.Store.AllNodes(); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	byID := make(map[int64]model.Node, len(nodes))
+	for _, n := range nodes {
+		byID[n.ID] = n
+	}
+	segs := make([]spatial.Segment, 0, len(pairs))
+	for _, pr := range pairs {
+		a, aok := byID[pr[0]]
+		b, bok := byID[pr[1]]
+		if aok && bok {
+			segs = append(segs, spatial.Segment{A: a, B: b})
+		}
+	}
+	grid := spatial.NewSegmentGrid(segs, snapCellMeters)
+	log.Printf("snap: indexed %d road segments in %v", grid.Len(), time.Since(start))
+	return grid, version, nil
+}
+
+// segmentKey is the snapSet key of the road between a and b.
+func segmentKey(a, b int64) [2]int64 {
+	return [2]int64{min(a, b), max(a, b)}
+}
+
+// snapTargets returns the nodes and roads mode accepts for profile p. Only
+// the first call for a mode and profile computes them in line; after the
+// graph changes the last set keeps being served while a fresh one is
+// computed in the background, so a road update costs snap requests neither
+// a reload of every edge nor the component search.
+func (s *Server) snapTargets(p algo.Profile, mode string) (snapSet, error) {
+	version := s.graphVersion()
+	key := mode
+	if mode != snapRoutable {
+		key += "/" + p.Name
+	}
+
+	s.snapMu.Lock()
+	set, ok := s.snapSets[key]
+	if ok && set.version != version && !s.snapRefreshing[key] {
+		if s.snapRefreshing == nil {
+			s.snapRefreshing = map[string]bool{}
+		}
+		s.snapRefreshing[key] = true
+		go func() {
+			next, err := s.buildSnapSet(p, mode)
+			if err != nil {
+				log.Printf("snap: refreshing %s: %v", key, err)
+			}
+			s.snapMu.Lock()
+			defer s.snapMu.Unlock()
+			delete(s.snapRefreshing, key)
+			if err == nil {
+				s.storeSnapSet(key, next)
+			}
+		}()
+	}
+	s.snapMu.Unlock()
+	if ok {
+		return set, nil
+	}
+
+	set, err := s.buildSnapSet(p, mode)
+	if err != nil {
+		return snapSet{}, err
+	}
+	s.snapMu.Lock()
+	defer s.snapMu.Unlock()
+	s.storeSnapSet(key, set)
+	return set, nil
+}
+
+// storeSnapSet keeps set under key unless a newer one is there already.
+// s.snapMu must be held.
+func (s *Server) storeSnapSet(key string, set snapSet) {
+	if s.snapSets == nil {
+		s.snapSets = map[string]snapSet{}
+	}
+	if cur, ok := s.snapSets[key]; !ok || cur.version <= set.version {
+		s.snapSets[key] = set
+	}
+}
+
+// buildSnapSet computes the nodes and roads mode accepts for profile p
+// from the current graph.
+func (s *Server) buildSnapSet(p algo.Profile, mode string) (snapSet, error) {
+	var edges []model.Edge
+	var version uint64
+	if s.Snaps != nil {
+		g := s.Snaps.Load()
+		version = g.Version()
+		if mode == snapRoutable {
+			edges = g.AllEdges()
+		} else {
+			edges = s.profiledSnapshot(g, p).AllEdges()
+		}
+	} else {
+		// read before the edges, so an update in between makes the set
+		// look older than it is rather than newer
+		version = s.RC.Epoch()
+		var err error
+		if edges, err = s.Store.AllEdges(); err != nil {
+			return snapSet{}, err
+		}
+		if mode != snapRoutable {
+			edges = p.Edges(edges)
+		}
+	}
+
+	set := snapSet{version: version, segments: map[[2]int64]bool{}}
+	if mode == snapComponent {
+		set.nodes = algo.LargestComponent(edges)
+		for _, e := range edges {
+			if set.nodes[e.Src] && set.nodes[e.Dst] {
+				set.segments[segmentKey(e.Src, e.Dst)] = true
+			}
+		}
+	} else {
+		set.nodes = map[int64]bool{}
+		for _, e := range edges {
+			set.nodes[e.Src], set.nodes[e.Dst] = true, true
+			set.segments[segmentKey(e.Src, e.Dst)] = true
+		}
+	}
+	return set, nil
+}
+
+// snapNode finds the node nearest c that mode accepts for profile p.
+func (s *Server) snapNode(c model.Coord, p algo.Profile, mode string) (*model.Snap, error) {
+	grid, err := s.nodeGrid()
+	if err != nil {
+		return nil, err
+	}
+	set, err := s.snapTargets(p, mode)
+	if err != nil {
+		return nil, err
+	}
+	n, d, ok := grid.Nearest(c, maxSnapMeters, func(id int64) bool { return set.nodes[id] })
+	if !ok {
+		return nil, fmt.Errorf("%w: nothing %s within %d m of %g,%g", errNoSnap, mode, maxSnapMeters, c.Lat, c.Lon)
+	}
+	return &model.Snap{Node: n.ID, Lat: n.Coord.Lat, Lon: n.Coord.Lon, DistanceM: d}, nil
+}
+
+// snapEdge projects c onto the nearest road mode accepts for profile p and
+// returns the virtual node, numbered id, that routes start or end at there.
+func (s *Server) snapEdge(c model.Coord, p algo.Profile, mode string, id int64) (*model.Snap, algo.Virtual, error) {
+	grid, err := s.segmentGrid()
+	if err != nil {
+		return nil, algo.Virtual{}, err
+	}
+	set, err := s.snapTargets(p, mode)
+	if err != nil {
+		return nil, algo.Virtual{}, err
+	}
+	pr, ok := grid.Nearest(c, maxSnapMeters, func(sg spatial.Segment) bool {
+		return set.segments[segmentKey(sg.A.ID, sg.B.ID)]
+	})
+	if !ok {
+		return nil, algo.Virtual{}, fmt.Errorf("%w: no %s road within %d m of %g,%g", errNoSnap, mode, maxSnapMeters, c.Lat, c.Lon)
+	}
+	sn := &model.Snap{
+		Segment:   []int64{pr.A.ID, pr.B.ID},
+		Fraction:  pr.Frac,
+		Lat:       pr.Coord.Lat,
+		Lon:       pr.Coord.Lon,
+		DistanceM: pr.DistM,
+	}
+	return sn, algo.Virtual{ID: id, A: pr.A.ID, B: pr.B.ID, Frac: pr.Frac, Coord: pr.Coord}, nil
+}
+
+// snapped finishes a /route?from=&to= response: it records the snaps, adds
+// the route's line from the first snapped point to the last, and takes the
+// virtual end nodes of edge snapping back out of the paths, which list only
+// graph nodes.
+func snapped(resp *model.RouteResponse, g algo.Graph, from, to *model.Snap) error {
+	resp.From, resp.To = from, to
+	if len(resp.Path) > 0 {
+		line := make([][2]float64, len(resp.Path))
+		for i, n := range resp.Path {
+			c, ok, err := g.Coord(n)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return fmt.Errorf("node %d has no coordinates", n)
+			}
+			line[i] = [2]float64{c.Lon, c.Lat}
+		}
+		resp.Geometry = &model.Geometry{Type: "LineString", Coordinates: line}
+	}
+
+	resp.Path = graphNodes(resp.Path)
+	for i := range resp.Alternatives {
+		resp.Alternatives[i].Path = graphNodes(resp.Alternatives[i].Path)
+	}
+	// pieces of an edge ending at a virtual node have ids of their own
+	for i, b := range resp.Blocked {
+		if b.EdgeID < 0 {
+			resp.Blocked[i].EdgeID = algo.SourceEdge(b.EdgeID)
+		}
+	}
+	return nil
+}
+
+// graphNodes returns path without the virtual nodes.
+func graphNodes(path []int64) []int64 {
+	if !slices.Contains(path, fromNode) && !slices.Contains(path, toNode) {
+		return path
+	}
+	out := make([]int64, 0, len(path))
+	for _, n := range path {
+		if n != fromNode && n != toNode {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// parseCoord reads "lat,lon".
+func parseCoord(s string) (model.Coord, error) {
+	f, err := parseFloats(s, ",")
+	if err != nil || len(f) != 2 || f[0] < -90 || f[0] > 90 || f[1] < -180 || f[1] > 180 {
+		return model.Coord{}, fmt.Errorf("bad coordinate %q, want lat,lon", s)
+	}
+	return model.Coord{Lat: f[0], Lon: f[1]}, nil
+}
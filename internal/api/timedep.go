@@ -0,0 +1,98 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/atharv3903/graphion/internal/algo"
+	"github.com/atharv3903/graphion/internal/model"
+)
+
+// EnableTraffic loads the speed profiles that /route?depart_at= and
+// arrive_by= search with. Bucket times are taken in loc. Without it both
+// still work, with every edge at its fixed speed.
+func (s *Server) EnableTraffic(loc *time.Location) error {
+	speeds, err := s.Store.TrafficProfiles(algo.WeekBuckets)
+	if err != nil {
+		return err
+	}
+	s.Traffic = algo.NewTraffic(speeds, loc)
+	log.Printf("traffic: %d speed profiles", s.Traffic.Len())
+	return nil
+}
+
+// parseTime reads an RFC 3339 time or Unix seconds.
+func parseTime(s string) (time.Time, error) {
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(n, 0), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("time must be RFC 3339 or Unix seconds, got %q", s)
+	}
+	return t, nil
+}
+
+// timedRoute answers /route?depart_at= and arrive_by=, with edge speeds
+// taken from the traffic profiles at the time each edge is reached. For
+// depart_at it finds the earliest arrival when leaving src at at; for
+// arrive_by (arrive set) the latest departure from src that reaches dst by
+// at, searching backwards from dst. astar adds the travel time heuristic.
+func (s *Server) timedRoute(sp routeSpec, src, dst int64, at time.Time, arrive, astar bool) (model.RouteResponse, error) {
+	var h algo.Heuristic
+	if astar {
+		// the backward search estimates the time from src instead
+		target := dst
+		if arrive {
+			target = src
+		}
+		var err error
+		if h, err = algo.GeoHeuristic(sp.g, target, algo.TravelTime, s.topSpeed(), s.stretch()); err != nil {
+			return model.RouteResponse{}, err
+		}
+	}
+
+	week := s.Traffic.WeekMillis(at)
+	search := algo.TimeDependent
+	if arrive {
+		search = algo.LatestDeparture
+	}
+	path, ms, explored, err := search(sp.search(sp.g), src, dst, s.Traffic, week, h)
+	if err != nil {
+		return model.RouteResponse{}, err
+	}
+
+	resp := model.RouteResponse{
+		Path:          path,
+		Total:         ms,
+		Metric:        algo.TravelTime.Name,
+		ExploredNodes: explored,
+		DepartAt:      &at,
+	}
+	if arrive {
+		resp.DepartAt, resp.ArriveBy = nil, &at
+	}
+	if len(path) == 0 {
+		return resp, nil
+	}
+
+	if arrive {
+		depart := at.Add(-time.Duration(ms) * time.Millisecond)
+		resp.DepartAt = &depart
+		// the trip itself, which may get there a little early
+		if resp.Total, err = s.Traffic.PathMillis(sp.g, path, week-ms); err != nil {
+			return model.RouteResponse{}, err
+		}
+	}
+	meters, _, err := algo.PathTotals(sp.g, path, algo.TravelTime.Cost)
+	if err != nil {
+		return model.RouteResponse{}, err
+	}
+	eta := resp.DepartAt.Add(time.Duration(resp.Total) * time.Millisecond)
+	resp.TotalMeters = meters
+	resp.TotalSeconds = float64(resp.Total) / 1000
+	resp.ETA = &eta
+	return resp, nil
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/atharv3903/graphion/internal/algo"
+	"github.com/atharv3903/graphion/internal/model"
+)
+
+// maxTripStops caps POST /trip; the cost matrix grows with its square.
+const maxTripStops = 100
+
+// handleTrip serves POST /trip: the cheapest order to visit the stops,
+// and the route through them in that order.
+func (s *Server) handleTrip(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST only", 405)
+		return
+	}
+
+	var req model.TripRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	if len(req.Stops) < 2 || len(req.Stops) > maxTripStops {
+		http.Error(w, "stops must list 2 to "+strconv.Itoa(maxTripStops)+" node ids", 400)
+		return
+	}
+	if req.RoundTrip && req.FixedEnd {
+		http.Error(w, "round_trip and fixed_end are exclusive", 400)
+		return
+	}
+
+	m, err := algo.ParseMetric(req.Metric)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	p, err := algo.ParseProfile(req.Profile)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	name := req.Algo
+	if name == "" {
+		name = "dijkstra"
+	}
+	rt, ok := s.Routers.Lookup(name)
+	if !ok {
+		http.Error(w, "unknown algo "+name, 400)
+		return
+	}
+	if err := checkRouter(rt, m, p); err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	end := algo.EndAnywhere
+	switch {
+	case req.RoundTrip:
+		end = algo.EndAtStart
+	case req.FixedEnd:
+		end = algo.EndAtLast
+	}
+
+	lim, ctx, cancel := s.requestLimits(r)
+	defer cancel()
+
+	g, epoch := s.routingGraph(ctx, p)
+
+	// engines searching g find the same costs OneToMany does; the others
+	// fill the matrix themselves so the order fits the legs they route
+	var mrt algo.Router
+	if rt.Capabilities().FixedGraph {
+		mrt = rt
+	}
+	costs, explored, err := s.costMatrix(lim.Graph(g), mrt, req.Stops, req.Stops, m)
+	if err != nil {
+		routeError(w, err, lim)
+		return
+	}
+	order, _, err := algo.SolveTrip(costs, end)
+	if errors.Is(err, algo.ErrNoTrip) {
+		http.Error(w, err.Error(), 422)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	resp := model.TripResponse{}
+	points := make([]int64, 0, len(order)+1)
+	for _, i := range order {
+		points = append(points, req.Stops[i])
+	}
+	resp.Order = append([]int64(nil), points...)
+	if end == algo.EndAtStart {
+		points = append(points, req.Stops[0])
+	}
+
+	sp := routeSpec{g: g, lim: lim, epoch: epoch, rt: rt, metric: m, profile: p}
+	resp.RouteResponse, err = s.viaRoute(sp, points)
+	if err != nil {
+		routeError(w, err, lim)
+		return
+	}
+	resp.ExploredNodes += explored
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/atharv3903/graphion/internal/model"
+)
+
+// maxWaypoints caps /route?points=; every leg is a search of its own.
+const maxWaypoints = 50
+
+func parsePoints(s string) ([]int64, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) < 2 || len(parts) > maxWaypoints {
+		return nil, fmt.Errorf("points must list 2 to %d node ids", maxWaypoints)
+	}
+	points := make([]int64, len(parts))
+	for i, p := range parts {
+		id, err := strconv.ParseInt(strings.TrimSpace(p), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad point %q", p)
+		}
+		points[i] = id
+	}
+	return points, nil
+}
+
+// viaRoute routes through points in order, one cached leg per consecutive
+// pair. If any leg has no path, neither does the whole route, but the legs
+// are still reported.
+func (s *Server) viaRoute(sp routeSpec, points []int64) (model.RouteResponse, error) {
+	resp := model.RouteResponse{Metric: sp.metric.Name, CacheHit: true}
+	complete := true
+
+	for i := 0; i+1 < len(points); i++ {
+		leg, err := s.cachedRoute(sp, points[i], points[i+1], 0)
+		if err != nil {
+			return model.RouteResponse{}, err
+		}
+		resp.Legs = append(resp.Legs, leg)
+
+		resp.ExploredNodes += leg.ExploredNodes
+		resp.CacheHit = resp.CacheHit && leg.CacheHit
+		if len(leg.Path) == 0 {
+			complete = false
+			continue
+		}
+
+		// each leg starts where the previous one ended
+		if len(resp.Path) > 0 {
+			resp.Path = append(resp.Path, leg.Path[1:]...)
+		} else {
+			resp.Path = append(resp.Path, leg.Path...)
+		}
+		resp.Total += leg.Total
+		resp.TotalMeters += leg.TotalMeters
+		resp.TotalSeconds += leg.TotalSeconds
+	}
+
+	if !complete {
+		resp.Path, resp.Total, resp.TotalMeters, resp.TotalSeconds = nil, 0, 0, 0
+	}
+	return resp, nil
+}
@@ -0,0 +1,244 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/atharv3903/graphion/internal/algo"
+	"github.com/atharv3903/graphion/internal/model"
+	"github.com/atharv3903/graphion/internal/vrp"
+)
+
+const (
+	maxVRPJobs     = 300
+	maxVRPVehicles = 50
+	// solves running at once; later submissions wait their turn
+	maxVRPSolves = 2
+	// submissions waiting for a solve slot; more are turned away
+	maxVRPQueued = 16
+
+	defaultVRPTimeLimit = 10 * time.Second
+	maxVRPTimeLimit     = 2 * time.Minute
+	// finished jobs are forgotten this long after they end
+	vrpJobTTL = time.Hour
+)
+
+type vrpJob struct {
+	status model.VRPStatus
+	ended  time.Time
+}
+
+// vrpJobs tracks POST /vrp jobs from submission until vrpJobTTL after they
+// finish.
+type vrpJobs struct {
+	mu    sync.Mutex
+	jobs  map[string]*vrpJob
+	slots chan struct{}
+	// held from submission until the job ends, by running and waiting jobs
+	pending chan struct{}
+}
+
+func newVRPJobs() *vrpJobs {
+	return &vrpJobs{
+		jobs:    map[string]*vrpJob{},
+		slots:   make(chan struct{}, maxVRPSolves),
+		pending: make(chan struct{}, maxVRPSolves+maxVRPQueued),
+	}
+}
+
+// admit reserves a place for a new job, and reports false when the queue
+// is full.
+func (q *vrpJobs) admit() bool {
+	select {
+	case q.pending <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (q *vrpJobs) add() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	id := hex.EncodeToString(b)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for k, j := range q.jobs {
+		if !j.ended.IsZero() && time.Since(j.ended) > vrpJobTTL {
+			delete(q.jobs, k)
+		}
+	}
+	q.jobs[id] = &vrpJob{status: model.VRPStatus{ID: id, Status: "queued"}}
+	return id
+}
+
+func (q *vrpJobs) set(id string, fn func(*model.VRPStatus)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	j := q.jobs[id]
+	fn(&j.status)
+	if j.status.Status == "done" || j.status.Status == "failed" {
+		j.ended = time.Now()
+	}
+}
+
+func (q *vrpJobs) get(id string) (model.VRPStatus, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	j, ok := q.jobs[id]
+	if !ok {
+		return model.VRPStatus{}, false
+	}
+	return j.status, true
+}
+
+// handleVRPSubmit serves POST /vrp. The request is checked and queued; the
+// matrix and the solve run in the background and GET /vrp/{id} reports
+// on them. A full queue is answered with 503.
+func (s *Server) handleVRPSubmit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST only", 405)
+		return
+	}
+	var req model.VRPRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	if len(req.Jobs) == 0 || len(req.Jobs) > maxVRPJobs {
+		http.Error(w, fmt.Sprintf("jobs must list 1 to %d jobs", maxVRPJobs), 400)
+		return
+	}
+	if len(req.Vehicles) == 0 || len(req.Vehicles) > maxVRPVehicles {
+		http.Error(w, fmt.Sprintf("vehicles must list 1 to %d vehicles", maxVRPVehicles), 400)
+		return
+	}
+	if _, err := algo.ParseProfile(req.Profile); err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	limit := defaultVRPTimeLimit
+	if req.TimeLimit > 0 {
+		limit = min(time.Duration(req.TimeLimit*float64(time.Second)), maxVRPTimeLimit)
+	}
+
+	if !s.vrp.admit() {
+		http.Error(w, "too many vrp jobs queued, try again later", 503)
+		return
+	}
+	id := s.vrp.add()
+	go s.runVRP(id, req, limit)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(model.VRPStatus{ID: id, Status: "queued"})
+}
+
+// handleVRPStatus serves GET /vrp/{id}.
+func (s *Server) handleVRPStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "GET only", 405)
+		return
+	}
+	st, ok := s.vrp.get(strings.TrimPrefix(r.URL.Path, "/vrp/"))
+	if !ok {
+		http.Error(w, "unknown vrp job", 404)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(st)
+}
+
+func (s *Server) runVRP(id string, req model.VRPRequest, limit time.Duration) {
+	defer func() { <-s.vrp.pending }()
+	s.vrp.slots <- struct{}{}
+	defer func() { <-s.vrp.slots }()
+
+	s.vrp.set(id, func(st *model.VRPStatus) { st.Status = "running" })
+
+	res, err := s.solveVRP(req, limit)
+	if err != nil {
+		log.Printf("vrp %s: %v", id, err)
+	}
+	s.vrp.set(id, func(st *model.VRPStatus) {
+		if err != nil {
+			st.Status, st.Error = "failed", err.Error()
+			return
+		}
+		st.Status, st.Result = "done", res
+	})
+}
+
+func (s *Server) solveVRP(req model.VRPRequest, limit time.Duration) (*model.VRPResult, error) {
+	ms := func(sec float64) int { return int(sec * 1000) }
+	sec := func(ms int) float64 { return float64(ms) / 1000 }
+
+	locations := []int64{req.Depot}
+	p := vrp.Problem{}
+	for _, j := range req.Jobs {
+		locations = append(locations, j.Node)
+		p.Jobs = append(p.Jobs, vrp.Job{
+			ID:       j.ID,
+			Demand:   j.Demand,
+			Earliest: ms(j.Earliest),
+			Latest:   ms(j.Latest),
+			Service:  ms(j.Service),
+		})
+	}
+	for _, v := range req.Vehicles {
+		p.Vehicles = append(p.Vehicles, vrp.Vehicle{
+			ID:       v.ID,
+			Capacity: v.Capacity,
+			Start:    ms(v.ShiftStart),
+			End:      ms(v.ShiftEnd),
+		})
+	}
+
+	// checked on submission
+	profile, _ := algo.ParseProfile(req.Profile)
+	g, _ := s.routingGraph(context.Background(), profile)
+	travel, _, err := s.costMatrix(g, nil, locations, locations, algo.TravelTime)
+	if err != nil {
+		return nil, err
+	}
+	// Unreachable is negative, which is what vrp expects for no path
+	p.Travel = travel
+
+	sol := vrp.Solve(p, limit)
+
+	res := &model.VRPResult{
+		Routes:        []model.VRPRoute{},
+		Unassigned:    []string{},
+		TravelSeconds: sec(sol.Travel),
+	}
+	for _, rt := range sol.Routes {
+		out := model.VRPRoute{
+			Vehicle:       req.Vehicles[rt.Vehicle].ID,
+			Load:          rt.Load,
+			TravelSeconds: sec(rt.Travel),
+			ReturnAt:      sec(rt.Return),
+		}
+		for _, st := range rt.Stops {
+			out.Stops = append(out.Stops, model.VRPStop{
+				Job:       req.Jobs[st.Job].ID,
+				Node:      req.Jobs[st.Job].Node,
+				Arrival:   sec(st.Arrival),
+				Departure: sec(st.Departure),
+			})
+		}
+		res.Routes = append(res.Routes, out)
+	}
+	for _, j := range sol.Unassigned {
+		res.Unassigned = append(res.Unassigned, req.Jobs[j].ID)
+	}
+	return res, nil
+}
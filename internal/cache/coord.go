@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/atharv3903/graphion/internal/model"
+)
+
+// coordinates are tiny compared to adjacency lists, so keep a lot more of them
+const defaultCoordCapacity = 65536
+
+type coordEntry struct {
+	key int64
+	val model.Coord
+}
+
+// CoordCache is an LRU of node positions, used by the A* heuristics so that
+// each node's lat/lon is fetched from MySQL at most once while it stays hot.
+type CoordCache struct {
+	mu       sync.Mutex
+	m        map[int64]*list.Element
+	ll       *list.List
+	capacity int
+}
+
+func NewCoordCache() *CoordCache {
+	return NewCoordCacheWithCap(defaultCoordCapacity)
+}
+
+func NewCoordCacheWithCap(capacity int) *CoordCache {
+	if capacity <= 0 {
+		capacity = defaultCoordCapacity
+	}
+	return &CoordCache{
+		m:        make(map[int64]*list.Element, capacity),
+		ll:       list.New(),
+		capacity: capacity,
+	}
+}
+
+func (c *CoordCache) Get(key int64) (model.Coord, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.m[key]; ok {
+		c.ll.MoveToFront(el)
+		return el.Value.(coordEntry).val, true
+	}
+	return model.Coord{}, false
+}
+
+func (c *CoordCache) Put(key int64, v model.Coord) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.m[key]; ok {
+		el.Value = coordEntry{key: key, val: v}
+		c.ll.MoveToFront(el)
+		return
+	}
+
+	c.m[key] = c.ll.PushFront(coordEntry{key: key, val: v})
+
+	if c.ll.Len() > c.capacity {
+		tail := c.ll.Back()
+		delete(c.m, tail.Value.(coordEntry).key)
+		c.ll.Remove(tail)
+	}
+}
+
+// Clear drops every cached position.
+func (c *CoordCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.m = make(map[int64]*list.Element, c.capacity)
+	c.ll.Init()
+}
@@ -1,32 +1,63 @@
 package cache
 
-import "sync"
+import (
+	"sync"
 
-type RouteKey struct{ Src, Dst int64; Algo string; Epoch uint64 }
+	"github.com/atharv3903/graphion/internal/model"
+)
+
+type RouteKey struct {
+	Src, Dst              int64
+	Algo, Metric, Profile string
+	Epoch                 uint64
+	Vehicle               model.Vehicle
+	Avoid                 uint64 // hash of the request's exclusions
+	// K is the number of routes asked for beyond a single path: k on
+	// /routes/k, alternatives on /route
+	K int
+}
 
 type RouteCache struct {
 	mu    sync.RWMutex
 	epoch uint64
-	m     map[RouteKey][]int64
+	m     map[RouteKey]model.RouteResponse
+	many  map[RouteKey]model.KRoutesResponse
 }
 
 func NewRouteCache() *RouteCache {
-	return &RouteCache{m: make(map[RouteKey][]int64)}
+	return &RouteCache{
+		m:    make(map[RouteKey]model.RouteResponse),
+		many: make(map[RouteKey]model.KRoutesResponse),
+	}
 }
 
-func (c *RouteCache) Get(k RouteKey) ([]int64, bool) {
+func (c *RouteCache) Get(k RouteKey) (model.RouteResponse, bool) {
 	c.mu.RLock()
 	v, ok := c.m[k]
 	c.mu.RUnlock()
 	return v, ok
 }
 
-func (c *RouteCache) Put(k RouteKey, p []int64) {
+func (c *RouteCache) Put(k RouteKey, p model.RouteResponse) {
 	c.mu.Lock()
 	c.m[k] = p
 	c.mu.Unlock()
 }
 
+// GetMany and PutMany hold multi-route answers such as /routes/k.
+func (c *RouteCache) GetMany(k RouteKey) (model.KRoutesResponse, bool) {
+	c.mu.RLock()
+	v, ok := c.many[k]
+	c.mu.RUnlock()
+	return v, ok
+}
+
+func (c *RouteCache) PutMany(k RouteKey, p model.KRoutesResponse) {
+	c.mu.Lock()
+	c.many[k] = p
+	c.mu.Unlock()
+}
+
 func (c *RouteCache) Epoch() uint64 {
 	c.mu.RLock()
 	e := c.epoch
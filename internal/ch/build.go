@@ -0,0 +1,240 @@
+package ch
+
+import (
+	"github.com/atharv3903/graphion/internal/algo"
+	"github.com/atharv3903/graphion/internal/model"
+)
+
+// Witness searches give up after settling this many nodes. A missed witness
+// only costs an unnecessary shortcut, never a wrong answer.
+const witnessSettleLimit = 500
+
+type arc struct {
+	from, to uint32
+	cost     int
+	child    [2]int32 // the two arcs a shortcut replaces, -1 for original edges
+}
+
+type builder struct {
+	ids        []int64
+	arcs       []arc
+	out, in    [][]int32 // arcs of the remaining (uncontracted) graph
+	contracted []bool
+	deleted    []int // contracted neighbours, spreads contraction evenly
+
+	// witness search scratch, reset by bumping gen
+	dist []int
+	seen []uint32
+	gen  uint32
+	pq   minHeap
+}
+
+// Build contracts the graph formed by edges under m, using the usual
+// edge-difference ordering with lazy priority updates. Parallel edges keep
+// the cheapest one and self loops are dropped.
+func Build(m algo.Metric, edges []model.Edge) *Hierarchy {
+	b := &builder{}
+
+	index := map[int64]uint32{}
+	node := func(id int64) uint32 {
+		if v, ok := index[id]; ok {
+			return v
+		}
+		v := uint32(len(b.ids))
+		index[id] = v
+		b.ids = append(b.ids, id)
+		return v
+	}
+
+	cheapest := map[[2]uint32]int32{}
+	for _, e := range edges {
+		u, v := node(e.Src), node(e.Dst)
+		if u == v {
+			continue
+		}
+		c := m.Cost(e.DistM, e.Speed)
+		if i, ok := cheapest[[2]uint32{u, v}]; ok {
+			b.arcs[i].cost = min(b.arcs[i].cost, c)
+			continue
+		}
+		cheapest[[2]uint32{u, v}] = int32(len(b.arcs))
+		b.arcs = append(b.arcs, arc{from: u, to: v, cost: c, child: [2]int32{-1, -1}})
+	}
+
+	n := len(b.ids)
+	b.out = make([][]int32, n)
+	b.in = make([][]int32, n)
+	for i, a := range b.arcs {
+		b.out[a.from] = append(b.out[a.from], int32(i))
+		b.in[a.to] = append(b.in[a.to], int32(i))
+	}
+	b.contracted = make([]bool, n)
+	b.deleted = make([]int, n)
+	b.dist = make([]int, n)
+	b.seen = make([]uint32, n)
+
+	var q minHeap
+	for v := 0; v < n; v++ {
+		q.push(uint32(v), b.priority(uint32(v)))
+	}
+
+	order := make([]uint32, 0, n)
+	for q.Len() > 0 {
+		v := q.pop().node
+		// priorities go stale as neighbours get contracted; re-queue v if
+		// it is no longer the cheapest choice
+		if p := b.priority(v); q.Len() > 0 && p > q[0].key {
+			q.push(v, p)
+			continue
+		}
+		b.contract(v, false)
+		order = append(order, v)
+	}
+
+	// renumber so that a node's dense index is its rank
+	rank := make([]uint32, n)
+	ids := make([]int64, n)
+	for r, v := range order {
+		rank[v] = uint32(r)
+		ids[r] = b.ids[v]
+	}
+	for i := range b.arcs {
+		b.arcs[i].from = rank[b.arcs[i].from]
+		b.arcs[i].to = rank[b.arcs[i].to]
+	}
+
+	h := newHierarchy(m.Name, ids, b.arcs)
+	h.Edges = algo.EdgeFingerprint(edges, m)
+	return h
+}
+
+func (b *builder) priority(v uint32) int {
+	added := b.contract(v, true)
+	removed := len(b.in[v]) + len(b.out[v])
+	return added - removed + b.deleted[v]
+}
+
+// contract adds the shortcuts needed to take v out of the remaining graph.
+// With dryRun it only counts them.
+func (b *builder) contract(v uint32, dryRun bool) int {
+	added := 0
+
+	for _, ai := range b.in[v] {
+		in := b.arcs[ai]
+		u := in.from
+
+		maxOut := -1
+		for _, bi := range b.out[v] {
+			if o := b.arcs[bi]; o.to != u {
+				maxOut = max(maxOut, o.cost)
+			}
+		}
+		if maxOut < 0 {
+			continue
+		}
+
+		b.witness(u, v, in.cost+maxOut)
+
+		for _, bi := range b.out[v] {
+			o := b.arcs[bi]
+			w := o.to
+			if w == u {
+				continue
+			}
+			need := in.cost + o.cost
+			if b.seen[w] == b.gen && b.dist[w] <= need {
+				continue
+			}
+			added++
+			if !dryRun {
+				b.addShortcut(u, w, need, ai, bi)
+			}
+		}
+	}
+
+	if dryRun {
+		return added
+	}
+
+	b.contracted[v] = true
+	for _, ai := range b.in[v] {
+		u := b.arcs[ai].from
+		b.deleted[u]++
+		b.out[u] = b.without(b.out[u], func(a arc) bool { return a.to == v })
+	}
+	for _, ai := range b.out[v] {
+		w := b.arcs[ai].to
+		b.deleted[w]++
+		b.in[w] = b.without(b.in[w], func(a arc) bool { return a.from == v })
+	}
+	b.in[v], b.out[v] = nil, nil
+
+	return added
+}
+
+// addShortcut links u to w through the arcs a1 and a2. A more expensive
+// u->w arc is dropped from the remaining graph but kept in b.arcs, since
+// earlier shortcuts may still unpack through it.
+func (b *builder) addShortcut(u, w uint32, cost int, a1, a2 int32) {
+	for _, ai := range b.out[u] {
+		if a := b.arcs[ai]; a.to == w && a.cost <= cost {
+			return
+		}
+	}
+	b.out[u] = b.without(b.out[u], func(a arc) bool { return a.to == w })
+	b.in[w] = b.without(b.in[w], func(a arc) bool { return a.from == u })
+
+	i := int32(len(b.arcs))
+	b.arcs = append(b.arcs, arc{from: u, to: w, cost: cost, child: [2]int32{a1, a2}})
+	b.out[u] = append(b.out[u], i)
+	b.in[w] = append(b.in[w], i)
+}
+
+func (b *builder) without(list []int32, drop func(arc) bool) []int32 {
+	kept := list[:0]
+	for _, ai := range list {
+		if !drop(b.arcs[ai]) {
+			kept = append(kept, ai)
+		}
+	}
+	return kept
+}
+
+// witness runs a bounded Dijkstra from src in the remaining graph without
+// skip. Afterwards dist/seen hold every node reached within limit.
+func (b *builder) witness(src, skip uint32, limit int) {
+	b.gen++
+	if b.gen == 0 {
+		clear(b.seen)
+		b.gen = 1
+	}
+	b.pq = b.pq[:0]
+	b.dist[src] = 0
+	b.seen[src] = b.gen
+	b.pq.push(src, 0)
+
+	settled := 0
+	for b.pq.Len() > 0 {
+		it := b.pq.pop()
+		if it.key > b.dist[it.node] {
+			continue
+		}
+		if it.key > limit || settled >= witnessSettleLimit {
+			return
+		}
+		settled++
+
+		for _, ai := range b.out[it.node] {
+			a := b.arcs[ai]
+			if a.to == skip {
+				continue
+			}
+			nd := it.key + a.cost
+			if b.seen[a.to] != b.gen || nd < b.dist[a.to] {
+				b.seen[a.to] = b.gen
+				b.dist[a.to] = nd
+				b.pq.push(a.to, nd)
+			}
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package ch
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/atharv3903/graphion/internal/algo"
+	"github.com/atharv3903/graphion/internal/graph"
+	"github.com/atharv3903/graphion/internal/model"
+)
+
+// testGrid builds an n by n street grid with random lengths and speeds and
+// one road direction in eight missing.
+func testGrid(n int, seed int64) ([]model.Node, []model.Edge) {
+	rnd := rand.New(rand.NewSource(seed))
+	id := func(i, j int) int64 { return int64(i*n + j + 1) }
+	var nodes []model.Node
+	var edges []model.Edge
+	add := func(a, b int64) {
+		if rnd.Intn(8) == 0 {
+			return
+		}
+		edges = append(edges, model.Edge{
+			ID: int64(len(edges) + 1), Src: a, Dst: b,
+			DistM: 10 + rnd.Intn(100),
+			Speed: []int{30, 50, 90}[rnd.Intn(3)],
+		})
+	}
+	for i := range n {
+		for j := range n {
+			nodes = append(nodes, model.Node{ID: id(i, j)})
+			if i+1 < n {
+				add(id(i, j), id(i+1, j))
+				add(id(i+1, j), id(i, j))
+			}
+			if j+1 < n {
+				add(id(i, j), id(i, j+1))
+				add(id(i, j+1), id(i, j))
+			}
+		}
+	}
+	return nodes, edges
+}
+
+func TestHierarchyMatchesDijkstra(t *testing.T) {
+	nodes, edges := testGrid(30, 1)
+	g := graph.New(nodes, edges)
+	for _, m := range []algo.Metric{algo.Distance, algo.TravelTime} {
+		h := Build(m, edges)
+		if h.Edges != algo.EdgeFingerprint(edges, m) {
+			t.Fatalf("%s: hierarchy fingerprint %x, edges %x", m.Name, h.Edges, algo.EdgeFingerprint(edges, m))
+		}
+		rnd := rand.New(rand.NewSource(2))
+		for range 300 {
+			src, dst := nodes[rnd.Intn(len(nodes))].ID, nodes[rnd.Intn(len(nodes))].ID
+			wantPath, want, _, err := algo.Dijkstra(g, src, dst, m.Cost)
+			if err != nil {
+				t.Fatal(err)
+			}
+			path, got, _ := h.Route(src, dst)
+			if len(wantPath) == 0 {
+				if path != nil {
+					t.Fatalf("%s %d -> %d: path where there is none", m.Name, src, dst)
+				}
+				continue
+			}
+			if got != want {
+				t.Fatalf("%s %d -> %d: %d, dijkstra %d", m.Name, src, dst, got, want)
+			}
+			if path[0] != src || path[len(path)-1] != dst {
+				t.Fatalf("%s %d -> %d: path runs %d -> %d", m.Name, src, dst, path[0], path[len(path)-1])
+			}
+			if pathCost(g, path, m.Cost) != want {
+				t.Fatalf("%s %d -> %d: unpacked path does not cost %d", m.Name, src, dst, want)
+			}
+		}
+	}
+}
+
+// pathCost sums the cheapest edge between each pair of consecutive nodes.
+func pathCost(g algo.Graph, path []int64, cost func(int, int) int) int {
+	total := 0
+	for i := 0; i+1 < len(path); i++ {
+		out, _ := g.Neighbors(path[i])
+		best := -1
+		for _, e := range out {
+			if c := cost(e.DistM, e.Speed); e.Dst == path[i+1] && (best < 0 || c < best) {
+				best = c
+			}
+		}
+		if best < 0 {
+			return -1
+		}
+		total += best
+	}
+	return total
+}
@@ -0,0 +1,196 @@
+package ch
+
+import "github.com/atharv3903/graphion/internal/db"
+
+// Hierarchy is a contracted graph ready for queries. Nodes are numbered by
+// rank, so an arc goes "up" when its head has the larger index.
+type Hierarchy struct {
+	Metric string
+	// Edges is the algo.EdgeFingerprint of the edges it was built from
+	Edges uint64
+
+	ids   []int64          // rank -> node id
+	index map[int64]uint32 // node id -> rank
+	arcs  []arc
+	up    [][]int32 // arcs leaving v towards higher ranks
+	down  [][]int32 // arcs entering v from higher ranks
+}
+
+func newHierarchy(metric string, ids []int64, arcs []arc) *Hierarchy {
+	h := &Hierarchy{
+		Metric: metric,
+		ids:    ids,
+		index:  make(map[int64]uint32, len(ids)),
+		arcs:   arcs,
+		up:     make([][]int32, len(ids)),
+		down:   make([][]int32, len(ids)),
+	}
+	for r, id := range ids {
+		h.index[id] = uint32(r)
+	}
+	for i, a := range arcs {
+		if a.from < a.to {
+			h.up[a.from] = append(h.up[a.from], int32(i))
+		} else {
+			h.down[a.to] = append(h.down[a.to], int32(i))
+		}
+	}
+	return h
+}
+
+// Nodes is the number of nodes in the hierarchy.
+func (h *Hierarchy) Nodes() int { return len(h.ids) }
+
+// Shortcuts is the number of arcs added by contraction.
+func (h *Hierarchy) Shortcuts() int {
+	n := 0
+	for _, a := range h.arcs {
+		if a.child[0] >= 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// Route answers a point-to-point query with an upward search from src and
+// an upward search over reversed arcs from dst, then unpacks the shortcuts
+// on the best meeting path. Nodes the hierarchy has never seen are
+// unreachable.
+func (h *Hierarchy) Route(src, dst int64) ([]int64, int, int) {
+	s, ok := h.index[src]
+	if !ok {
+		return nil, 0, 0
+	}
+	t, ok := h.index[dst]
+	if !ok {
+		return nil, 0, 0
+	}
+	if s == t {
+		return []int64{src}, 0, 0
+	}
+
+	dist := [2]map[uint32]int{{s: 0}, {t: 0}}
+	via := [2]map[uint32]int32{{}, {}} // arc each side reached a node by
+	var q [2]minHeap
+	q[0].push(s, 0)
+	q[1].push(t, 0)
+
+	best, meet, found := 0, uint32(0), false
+	explored := 0
+
+	for q[0].Len() > 0 || q[1].Len() > 0 {
+		side := 0
+		if q[0].Len() == 0 || (q[1].Len() > 0 && q[1][0].key < q[0][0].key) {
+			side = 1
+		}
+
+		it := q[side].pop()
+		v := it.node
+		if it.key > dist[side][v] {
+			continue
+		}
+		// nothing left on this side can improve best
+		if found && it.key >= best {
+			q[side] = q[side][:0]
+			continue
+		}
+		explored++
+
+		if od, ok := dist[1-side][v]; ok && (!found || it.key+od < best) {
+			best, meet, found = it.key+od, v, true
+		}
+
+		adj := h.up[v]
+		if side == 1 {
+			adj = h.down[v]
+		}
+		for _, ai := range adj {
+			a := h.arcs[ai]
+			w := a.to
+			if side == 1 {
+				w = a.from
+			}
+			nd := it.key + a.cost
+			if old, ok := dist[side][w]; ok && nd >= old {
+				continue
+			}
+			dist[side][w] = nd
+			via[side][w] = ai
+			q[side].push(w, nd)
+		}
+	}
+
+	if !found {
+		return nil, 0, explored
+	}
+
+	// arcs from s up to meet, collected backwards
+	var upArcs []int32
+	for v := meet; v != s; v = h.arcs[via[0][v]].from {
+		upArcs = append(upArcs, via[0][v])
+	}
+
+	path := []int64{src}
+	for i := len(upArcs) - 1; i >= 0; i-- {
+		path = h.unpack(upArcs[i], path)
+	}
+	for v := meet; v != t; v = h.arcs[via[1][v]].to {
+		path = h.unpack(via[1][v], path)
+	}
+
+	return path, best, explored
+}
+
+// unpack appends the original nodes after the tail of arc ai.
+func (h *Hierarchy) unpack(ai int32, path []int64) []int64 {
+	a := h.arcs[ai]
+	if a.child[0] < 0 {
+		return append(path, h.ids[a.to])
+	}
+	path = h.unpack(a.child[0], path)
+	return h.unpack(a.child[1], path)
+}
+
+// Save persists h through store, replacing whatever was stored for its
+// metric.
+func Save(store db.Store, h *Hierarchy) error {
+	arcs := make([]db.CHArc, len(h.arcs))
+	for i, a := range h.arcs {
+		arcs[i] = db.CHArc{
+			Src:    h.ids[a.from],
+			Dst:    h.ids[a.to],
+			Cost:   a.cost,
+			Child1: a.child[0],
+			Child2: a.child[1],
+		}
+	}
+	return store.SaveHierarchy(h.Metric, h.Edges, h.ids, arcs)
+}
+
+// Load reads a hierarchy saved for metric. It returns nil when nothing is
+// stored.
+func Load(store db.Store, metric string) (*Hierarchy, error) {
+	edges, ids, stored, err := store.LoadHierarchy(metric)
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	rank := make(map[int64]uint32, len(ids))
+	for r, id := range ids {
+		rank[id] = uint32(r)
+	}
+
+	arcs := make([]arc, len(stored))
+	for i, a := range stored {
+		arcs[i] = arc{
+			from:  rank[a.Src],
+			to:    rank[a.Dst],
+			cost:  a.Cost,
+			child: [2]int32{a.Child1, a.Child2},
+		}
+	}
+
+	h := newHierarchy(metric, ids, arcs)
+	h.Edges = edges
+	return h, nil
+}
@@ -0,0 +1,125 @@
+package ch
+
+import (
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/atharv3903/graphion/internal/algo"
+	"github.com/atharv3903/graphion/internal/db"
+)
+
+type version struct {
+	h   *Hierarchy
+	gen uint64
+}
+
+// Manager owns the live hierarchy for one metric. Road updates call
+// Invalidate, which retires the current hierarchy at once and re-contracts
+// the graph in the background. Until the rebuild lands Current reports
+// false, and callers are expected to fall back to a search on the raw graph.
+// Updates that arrive mid-rebuild are folded into one more rebuild.
+type Manager struct {
+	Metric algo.Metric
+
+	store db.Store
+
+	cur atomic.Pointer[version]
+	gen atomic.Uint64 // bumped by every Invalidate
+
+	mu      sync.Mutex
+	running bool
+}
+
+func NewManager(store db.Store, metric algo.Metric) *Manager {
+	return &Manager{Metric: metric, store: store}
+}
+
+// Start loads the persisted hierarchy for the metric, or contracts the
+// graph from scratch if none is stored or the edges have changed since it
+// was built. It does not block.
+func (m *Manager) Start() {
+	go func() {
+		gen := m.gen.Load()
+		h, err := Load(m.store, m.Metric.Name)
+		if err != nil {
+			log.Printf("ch %s: load failed, rebuilding: %v", m.Metric.Name, err)
+		}
+		if h == nil {
+			m.Invalidate()
+			return
+		}
+		edges, err := m.store.AllEdges()
+		if err != nil {
+			log.Printf("ch %s: loading edges failed, rebuilding: %v", m.Metric.Name, err)
+			m.Invalidate()
+			return
+		}
+		if algo.EdgeFingerprint(algo.DefaultProfile.Edges(edges), m.Metric) != h.Edges {
+			log.Printf("ch %s: stored hierarchy was built from other edges, rebuilding", m.Metric.Name)
+			m.Invalidate()
+			return
+		}
+		m.cur.Store(&version{h: h, gen: gen})
+		log.Printf("ch %s: loaded %d nodes, %d shortcuts", m.Metric.Name, h.Nodes(), h.Shortcuts())
+	}()
+}
+
+// Current returns the hierarchy if it reflects the latest road state.
+func (m *Manager) Current() (*Hierarchy, bool) {
+	v := m.cur.Load()
+	if v == nil || v.gen != m.gen.Load() {
+		return nil, false
+	}
+	return v.h, true
+}
+
+// Invalidate marks the hierarchy stale and schedules a rebuild.
+func (m *Manager) Invalidate() {
+	m.gen.Add(1)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.running {
+		// the running loop sees the new gen when it finishes
+		return
+	}
+	m.running = true
+	go m.rebuildLoop()
+}
+
+func (m *Manager) rebuildLoop() {
+	for {
+		gen := m.gen.Load()
+		if err := m.rebuild(gen); err != nil {
+			log.Printf("ch %s: rebuild failed: %v", m.Metric.Name, err)
+		}
+
+		m.mu.Lock()
+		if m.gen.Load() == gen {
+			m.running = false
+			m.mu.Unlock()
+			return
+		}
+		m.mu.Unlock()
+	}
+}
+
+func (m *Manager) rebuild(gen uint64) error {
+	start := time.Now()
+
+	edges, err := m.store.AllEdges()
+	if err != nil {
+		return err
+	}
+	h := Build(m.Metric, algo.DefaultProfile.Edges(edges))
+	m.cur.Store(&version{h: h, gen: gen})
+	log.Printf("ch %s: built %d nodes, %d shortcuts in %v", m.Metric.Name, h.Nodes(), h.Shortcuts(), time.Since(start))
+
+	// a newer update already made this one stale, don't bother saving it
+	if m.gen.Load() != gen {
+		return nil
+	}
+	return Save(m.store, h)
+}
@@ -0,0 +1,29 @@
+package ch
+
+import "github.com/atharv3903/graphion/internal/algo"
+
+// Name, Capabilities and Route make the Manager the algo=ch engine. Queries
+// that arrive while the hierarchy is missing or stale are answered by
+// Dijkstra.
+func (m *Manager) Name() string { return "ch" }
+
+func (m *Manager) Capabilities() algo.Capabilities {
+	_, ready := m.Current()
+	return algo.Capabilities{
+		Metrics:       []string{m.Metric.Name},
+		Profiles:      []string{algo.DefaultProfile.Name},
+		Preprocessing: true,
+		FixedGraph:    true,
+		Ready:         ready,
+	}
+}
+
+func (m *Manager) Route(g algo.Graph, q algo.Query) (algo.Result, error) {
+	h, ok := m.Current()
+	if !ok {
+		path, total, explored, err := algo.Dijkstra(g, q.Src, q.Dst, q.Metric.Cost)
+		return algo.Result{Path: path, Total: total, Explored: explored}, err
+	}
+	path, total, explored := h.Route(q.Src, q.Dst)
+	return algo.Result{Path: path, Total: total, Explored: explored}, nil
+}
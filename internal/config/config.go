@@ -3,21 +3,105 @@ package config
 import (
 	"flag"
 	"os"
+	"runtime"
+	"time"
 )
 
 type ServerConfig struct {
 	MySQLDSN string
 	Addr     string
+	InMemory bool
+	CH       bool
+	CHMetric string
+
+	CRP       bool
+	CRPMetric string
+
+	ALTPath      string
+	ALTLandmarks int
+	ALTStrategy  string
+	ALTMetric    string
+
+	AlternativesMaxOverlap float64
+	AlternativesMaxStretch float64
+
+	MatrixWorkers int
+
+	MaxExplored   int
+	SearchTimeout time.Duration
+
+	Turns        bool
+	UTurnSeconds float64
+
+	Traffic   bool
+	TrafficTZ string
 }
 
 func FromFlagsServer() ServerConfig {
 	var dsn, addr string
+	var ch, inMemory bool
+	var chMetric string
+	var crp bool
+	var crpMetric string
+	var altPath, altStrategy, altMetric string
+	var altLandmarks int
+	var altOverlap, altStretch float64
+	var matrixWorkers int
+	var maxExplored int
+	var searchTimeout time.Duration
+	var turns bool
+	var uturn float64
+	var traffic bool
+	var trafficTZ string
 	flag.StringVar(&dsn, "dsn", os.Getenv("DB_DSN"), "MySQL DSN")
 	flag.StringVar(&addr, "addr", ":8080", "HTTP bind address")
+	flag.BoolVar(&inMemory, "inmem", false, "load the graph into memory at startup instead of querying MySQL per node")
+	flag.BoolVar(&ch, "ch", false, "load or build a contraction hierarchy for algo=ch")
+	flag.StringVar(&chMetric, "ch-metric", "distance", "metric the contraction hierarchy is built for")
+	flag.BoolVar(&crp, "crp", false, "partition the in-memory graph and customize a CRP overlay for algo=crp (needs -inmem)")
+	flag.StringVar(&crpMetric, "crp-metric", "time", "metric the CRP overlay is customized for")
+	flag.StringVar(&altPath, "alt", "", "landmark table file for algo=alt, built if missing")
+	flag.IntVar(&altLandmarks, "alt-landmarks", 16, "number of ALT landmarks to select")
+	flag.StringVar(&altStrategy, "alt-strategy", "avoid", "ALT landmark selection: avoid or farthest")
+	flag.StringVar(&altMetric, "alt-metric", "distance", "metric the landmark tables are built for")
+	flag.Float64Var(&altOverlap, "alternatives-overlap", 0.6, "largest share of another route an alternative route may run along")
+	flag.Float64Var(&altStretch, "alternatives-stretch", 1.4, "largest cost of an alternative route relative to the best one")
+	flag.IntVar(&matrixWorkers, "matrix-workers", runtime.NumCPU(), "matrix searches running at once across all requests")
+	flag.IntVar(&maxExplored, "max-explored", 0, "nodes one routing request may expand before it is aborted, 0 for no limit")
+	flag.DurationVar(&searchTimeout, "search-timeout", 0, "time one routing request may search before it is aborted, 0 for no limit")
+	flag.BoolVar(&turns, "turns", false, "load turn restrictions for algo=turns")
+	flag.Float64Var(&uturn, "uturn-penalty", 30, "seconds charged for a U-turn under algo=turns, negative forbids them")
+	flag.BoolVar(&traffic, "traffic", false, "load speed profiles for /route?depart_at=")
+	flag.StringVar(&trafficTZ, "traffic-tz", "Local", "time zone the speed profile buckets are in")
 	flag.Parse()
 
 	return ServerConfig{
 		MySQLDSN: dsn,
 		Addr:     addr,
+		InMemory: inMemory,
+		CH:       ch,
+		CHMetric: chMetric,
+
+		CRP:       crp,
+		CRPMetric: crpMetric,
+
+		ALTPath:      altPath,
+		ALTLandmarks: altLandmarks,
+		ALTStrategy:  altStrategy,
+		ALTMetric:    altMetric,
+
+		AlternativesMaxOverlap: altOverlap,
+		AlternativesMaxStretch: altStretch,
+
+		MatrixWorkers: matrixWorkers,
+
+		MaxExplored:   maxExplored,
+		SearchTimeout: searchTimeout,
+
+		Turns:        turns,
+		UTurnSeconds: uturn,
+
+		Traffic:   traffic,
+		TrafficTZ: trafficTZ,
 	}
 }
@@ -0,0 +1,29 @@
+package crp
+
+import "container/heap"
+
+type item struct {
+	node uint32
+	key  int
+}
+
+type minHeap []item
+
+func (h minHeap) Len() int           { return len(h) }
+func (h minHeap) Less(i, j int) bool { return h[i].key < h[j].key }
+func (h minHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *minHeap) Push(x any) {
+	*h = append(*h, x.(item))
+}
+
+func (h *minHeap) Pop() any {
+	old := *h
+	n := len(old)
+	it := old[n-1]
+	*h = old[:n-1]
+	return it
+}
+
+func (h *minHeap) push(node uint32, key int) { heap.Push(h, item{node: node, key: key}) }
+func (h *minHeap) pop() item                 { return heap.Pop(h).(item) }
@@ -0,0 +1,159 @@
+package crp
+
+import (
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/atharv3903/graphion/internal/algo"
+	"github.com/atharv3903/graphion/internal/graph"
+)
+
+// Manager owns the live overlay for one metric. Start partitions the graph
+// and customizes every cell in the background; Update then moves the overlay
+// to each new graph snapshot by re-customizing only the cells that hold the
+// changed edge. Until the first build lands, and for graphs the overlay was
+// not built for, queries fall back to a search on the graph itself.
+type Manager struct {
+	Metric algo.Metric
+	// Graph returns the graph algo=crp requests are currently routed on,
+	// which Capabilities checks the overlay against. Nil means the graph of
+	// the last Start or Update.
+	Graph func() *graph.CSR
+
+	cur atomic.Pointer[Overlay]
+
+	mu       sync.Mutex
+	latest   *graph.CSR
+	building bool
+	// pending are the edges changed since the running build read its graph
+	pending []change
+}
+
+// change is one Update: the edge src->dst got a new speed, or was opened or
+// closed when structural is set.
+type change struct {
+	src, dst   int64
+	structural bool
+}
+
+func NewManager(metric algo.Metric) *Manager {
+	return &Manager{Metric: metric}
+}
+
+// Start partitions and customizes g. It does not block.
+func (m *Manager) Start(g *graph.CSR) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.latest = g
+	m.build()
+}
+
+// Update moves the overlay to g, which differs from the graph of the last
+// Start or Update by the edge src->dst. structural is set when the edge was
+// opened or closed rather than given a new speed, since that can move cell
+// boundaries. While a build is running the change is kept and applied to its
+// overlay once it lands. If g no longer shares the node table the overlay is
+// rebuilt from scratch instead.
+func (m *Manager) Update(g *graph.CSR, src, dst int64, structural bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.latest = g
+	if m.building {
+		m.pending = append(m.pending, change{src, dst, structural})
+		return
+	}
+	o := m.cur.Load()
+	if o == nil || !g.SharesNodes(o.g) {
+		m.build()
+		return
+	}
+	m.cur.Store(m.recustomize(o, g, []change{{src, dst, structural}}))
+}
+
+// recustomize moves o to g, which differs from o's graph by changes.
+func (m *Manager) recustomize(o *Overlay, g *graph.CSR, changes []change) *Overlay {
+	start := time.Now()
+	dirty := make([]map[uint32]bool, o.part.Levels)
+	for l := range dirty {
+		dirty[l] = map[uint32]bool{}
+	}
+	boundaries := false
+	for _, ch := range changes {
+		boundaries = boundaries || ch.structural
+		u, uok := g.Index(ch.src)
+		v, vok := g.Index(ch.dst)
+		if !uok || !vok || !(ch.structural || m.Metric.UsesSpeed) {
+			continue
+		}
+		for l := 1; l <= o.part.Levels; l++ {
+			cu, cv := o.part.Cell(l, u), o.part.Cell(l, v)
+			switch {
+			case cu == cv:
+				dirty[l-1][cu] = true
+			case ch.structural:
+				dirty[l-1][cu] = true
+				dirty[l-1][cv] = true
+			}
+		}
+	}
+	next := o.Recustomize(g, dirty, boundaries)
+
+	n := 0
+	for _, d := range dirty {
+		n += len(d)
+	}
+	log.Printf("crp %s: re-customized %d cells for %d changes in %s", m.Metric.Name, n, len(changes), time.Since(start))
+	return next
+}
+
+// build runs a full partition and customization of latest in the
+// background. The overlay is published for the graph it was built on, and
+// the changes made meanwhile are then re-customized into it; only a new node
+// table makes it build again. m.mu must be held.
+func (m *Manager) build() {
+	m.building = true
+	m.pending = nil
+	go func() {
+		m.mu.Lock()
+		g := m.latest
+		m.mu.Unlock()
+
+		for {
+			start := time.Now()
+			part := NewPartition(g)
+			o := Customize(g, part, m.Metric)
+			log.Printf("crp %s: %d levels over %d nodes built in %s", m.Metric.Name, part.Levels, g.Len(), time.Since(start))
+
+			m.mu.Lock()
+			m.cur.Store(o)
+			if m.latest == g {
+				m.building = false
+				m.mu.Unlock()
+				return
+			}
+			if m.latest.SharesNodes(g) {
+				m.cur.Store(m.recustomize(o, m.latest, m.pending))
+				m.building, m.pending = false, nil
+				m.mu.Unlock()
+				return
+			}
+			g, m.pending = m.latest, nil
+			m.mu.Unlock()
+		}
+	}()
+}
+
+// Current returns the overlay if it was customized for g. Request limits on
+// g are looked through; an overlay query is bounded by the cell sizes.
+func (m *Manager) Current(g algo.Graph) (*Overlay, bool) {
+	o := m.cur.Load()
+	if o == nil {
+		return nil, false
+	}
+	if cg, ok := algo.Unlimited(g).(*graph.CSR); !ok || cg != o.g {
+		return nil, false
+	}
+	return o, true
+}
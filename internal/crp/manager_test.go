@@ -0,0 +1,77 @@
+package crp
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/atharv3903/graphion/internal/algo"
+	"github.com/atharv3903/graphion/internal/graph"
+	"github.com/atharv3903/graphion/internal/model"
+)
+
+// changeEdge returns g with one random edge either removed (structural) or
+// given a new speed, and that edge.
+func changeEdge(g *graph.CSR, rnd *rand.Rand) (*graph.CSR, model.Edge, bool) {
+	edges := g.AllEdges()
+	target := edges[rnd.Intn(len(edges))]
+	structural := rnd.Intn(3) == 0
+	speed := []int{5, 120}[rnd.Intn(2)]
+	return g.WithEdges(func(edges []model.Edge) []model.Edge {
+		out := edges[:0]
+		for _, e := range edges {
+			if e.ID == target.ID {
+				if structural {
+					continue
+				}
+				e.Speed = speed
+			}
+			out = append(out, e)
+		}
+		return out
+	}), target, structural
+}
+
+func TestManagerUpdates(t *testing.T) {
+	g := testGrid(30, 5)
+	rnd := rand.New(rand.NewSource(6))
+	m := NewManager(algo.TravelTime)
+	m.cur.Store(Customize(g, NewPartition(g), m.Metric))
+	m.latest = g
+	for range 20 {
+		var e model.Edge
+		var structural bool
+		g, e, structural = changeEdge(g, rnd)
+		m.Update(g, e.Src, e.Dst, structural)
+		o, ok := m.Current(g)
+		if !ok {
+			t.Fatal("overlay not current after an update")
+		}
+		checkOverlay(t, o, g, rnd)
+	}
+}
+
+func TestManagerUpdatesDuringBuild(t *testing.T) {
+	g := testGrid(60, 7)
+	rnd := rand.New(rand.NewSource(5))
+	m := NewManager(algo.TravelTime)
+	m.Start(g)
+	for range 30 {
+		var e model.Edge
+		var structural bool
+		g, e, structural = changeEdge(g, rnd)
+		m.Update(g, e.Src, e.Dst, structural)
+		time.Sleep(2 * time.Millisecond)
+	}
+	deadline := time.Now().Add(30 * time.Second)
+	for {
+		if o, ok := m.Current(g); ok {
+			checkOverlay(t, o, g, rnd)
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("overlay never caught up with the updates")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
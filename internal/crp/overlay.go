@@ -0,0 +1,177 @@
+package crp
+
+import (
+	"github.com/atharv3903/graphion/internal/algo"
+	"github.com/atharv3903/graphion/internal/graph"
+)
+
+const unreachable = -1
+
+// cellData is the customized overlay of one cell: the nodes where paths
+// enter and leave it, and the cheapest cost from every entry to every exit
+// without leaving the cell.
+type cellData struct {
+	entries, exits    []uint32
+	entryIdx, exitIdx map[uint32]int
+	cost              []int // entry i to exit j at i*len(exits)+j
+}
+
+func (c *cellData) clique(i, j int) int { return c.cost[i*len(c.exits)+j] }
+
+// Overlay is a partition customized for one metric on one graph. It is
+// never modified once built; Recustomize returns a new Overlay that shares
+// every untouched cell with the old one.
+type Overlay struct {
+	g      *graph.CSR
+	metric algo.Metric
+	part   *Partition
+	cells  [][]*cellData // [level-1][cell]
+}
+
+// Customize computes the overlay of every cell of part.
+func Customize(g *graph.CSR, part *Partition, metric algo.Metric) *Overlay {
+	o := &Overlay{g: g, metric: metric, part: part, cells: make([][]*cellData, part.Levels)}
+	for l := 1; l <= part.Levels; l++ {
+		o.cells[l-1] = make([]*cellData, part.Cells(l))
+		for c := range o.cells[l-1] {
+			o.cells[l-1][c] = o.customize(l, uint32(c), nil)
+		}
+	}
+	return o
+}
+
+// Recustomize returns the overlay of g, which must share o's node table,
+// recomputing only the cells in dirty (per level). Unless boundaries is set
+// the dirty cells keep their entries and exits, which holds as long as no
+// edge was opened or closed.
+func (o *Overlay) Recustomize(g *graph.CSR, dirty []map[uint32]bool, boundaries bool) *Overlay {
+	next := &Overlay{g: g, metric: o.metric, part: o.part, cells: make([][]*cellData, len(o.cells))}
+	for l := 1; l <= o.part.Levels; l++ {
+		next.cells[l-1] = append([]*cellData(nil), o.cells[l-1]...)
+		for c := range dirty[l-1] {
+			old := o.cells[l-1][c]
+			if boundaries {
+				old = nil
+			}
+			next.cells[l-1][c] = next.customize(l, c, old)
+		}
+	}
+	return next
+}
+
+// customize computes cell c at level l, taking the boundary from keep when
+// it is given. Cells of the levels below must already be customized.
+func (o *Overlay) customize(l int, c uint32, keep *cellData) *cellData {
+	cd := &cellData{}
+	if keep != nil {
+		cd.entries, cd.exits, cd.entryIdx, cd.exitIdx = keep.entries, keep.exits, keep.entryIdx, keep.exitIdx
+	} else {
+		o.boundary(l, c, cd)
+	}
+
+	cd.cost = make([]int, len(cd.entries)*len(cd.exits))
+	for i, x := range cd.entries {
+		dist, _ := o.cellSearch(l, c, x, 0, false)
+		for j, y := range cd.exits {
+			d, ok := dist[y]
+			if !ok {
+				d = unreachable
+			}
+			cd.cost[i*len(cd.exits)+j] = d
+		}
+	}
+	return cd
+}
+
+// boundary finds the nodes of cell c with an edge from or to another cell.
+func (o *Overlay) boundary(l int, c uint32, cd *cellData) {
+	cd.entryIdx, cd.exitIdx = map[uint32]int{}, map[uint32]int{}
+	for _, leaf := range o.part.Members(l, c) {
+		for _, v := range leaf {
+			_, heads := o.g.Out(v)
+			for _, w := range heads {
+				if o.part.Cell(l, w) != c {
+					cd.exitIdx[v] = len(cd.exits)
+					cd.exits = append(cd.exits, v)
+					break
+				}
+			}
+			_, tails := o.g.In(v)
+			for _, w := range tails {
+				if o.part.Cell(l, w) != c {
+					cd.entryIdx[v] = len(cd.entries)
+					cd.entries = append(cd.entries, v)
+					break
+				}
+			}
+		}
+	}
+}
+
+// hop is how a cell search reached a node: over an edge of the graph, or
+// over the clique of a cell one level down.
+type hop struct {
+	from   uint32
+	clique bool
+}
+
+// cellSearch runs Dijkstra from src without leaving cell c at level l. At
+// level 1 it walks the graph's edges; above that it walks the cliques of the
+// subcells and the edges between them. With stop set it ends once dst is
+// settled.
+func (o *Overlay) cellSearch(l int, c uint32, src, dst uint32, stop bool) (map[uint32]int, map[uint32]hop) {
+	dist := map[uint32]int{src: 0}
+	prev := map[uint32]hop{}
+	h := &minHeap{}
+	h.push(src, 0)
+
+	relax := func(v uint32, d int, via hop) {
+		if old, ok := dist[v]; ok && old <= d {
+			return
+		}
+		dist[v] = d
+		prev[v] = via
+		h.push(v, d)
+	}
+
+	for h.Len() > 0 {
+		it := h.pop()
+		u := it.node
+		if it.key > dist[u] {
+			continue
+		}
+		if stop && u == dst {
+			break
+		}
+
+		if l == 1 {
+			edges, heads := o.g.Out(u)
+			for k, e := range edges {
+				if o.part.Cell(1, heads[k]) == c {
+					relax(heads[k], it.key+o.metric.Cost(e.DistM, e.Speed), hop{from: u})
+				}
+			}
+			continue
+		}
+
+		sc := o.part.Cell(l-1, u)
+		sub := o.cells[l-2][sc]
+		if i, ok := sub.entryIdx[u]; ok {
+			for j, x := range sub.exits {
+				if d := sub.clique(i, j); d != unreachable {
+					relax(x, it.key+d, hop{from: u, clique: true})
+				}
+			}
+		}
+		if _, ok := sub.exitIdx[u]; ok {
+			edges, heads := o.g.Out(u)
+			for k, e := range edges {
+				w := heads[k]
+				if o.part.Cell(l, w) == c && o.part.Cell(l-1, w) != sc {
+					relax(w, it.key+o.metric.Cost(e.DistM, e.Speed), hop{from: u})
+				}
+			}
+		}
+	}
+	return dist, prev
+}
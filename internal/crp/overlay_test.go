@@ -0,0 +1,100 @@
+package crp
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/atharv3903/graphion/internal/algo"
+	"github.com/atharv3903/graphion/internal/graph"
+	"github.com/atharv3903/graphion/internal/model"
+)
+
+// testGrid builds an n by n street grid of about 55 m blocks with random
+// speeds and one road direction in eight missing.
+func testGrid(n int, seed int64) *graph.CSR {
+	rnd := rand.New(rand.NewSource(seed))
+	id := func(i, j int) int64 { return int64(i*n + j + 1) }
+	var nodes []model.Node
+	for i := range n {
+		for j := range n {
+			nodes = append(nodes, model.Node{ID: id(i, j), Coord: model.Coord{
+				Lat: 18.5 + float64(i)*0.0005 + rnd.Float64()*0.0002,
+				Lon: 73.8 + float64(j)*0.0005 + rnd.Float64()*0.0002,
+			}})
+		}
+	}
+	var edges []model.Edge
+	add := func(a, b int64) {
+		if rnd.Intn(8) == 0 {
+			return
+		}
+		edges = append(edges, model.Edge{
+			ID: int64(len(edges) + 1), Src: a, Dst: b,
+			DistM: int(algo.Haversine(nodes[a-1].Coord, nodes[b-1].Coord)),
+			Speed: []int{30, 40, 60, 90}[rnd.Intn(4)],
+		})
+	}
+	for i := range n {
+		for j := range n {
+			if i+1 < n {
+				add(id(i, j), id(i+1, j))
+				add(id(i+1, j), id(i, j))
+			}
+			if j+1 < n {
+				add(id(i, j), id(i, j+1))
+				add(id(i, j+1), id(i, j))
+			}
+		}
+	}
+	return graph.New(nodes, edges)
+}
+
+// checkOverlay compares queries on o with Dijkstra on g, which o must be
+// customized for.
+func checkOverlay(t *testing.T, o *Overlay, g *graph.CSR, rnd *rand.Rand) {
+	t.Helper()
+	for range 100 {
+		src, dst := g.ID(uint32(rnd.Intn(g.Len()))), g.ID(uint32(rnd.Intn(g.Len())))
+		wantPath, want, _, err := algo.Dijkstra(g, src, dst, o.metric.Cost)
+		if err != nil {
+			t.Fatal(err)
+		}
+		path, got, _ := o.Route(src, dst)
+		if (wantPath == nil) != (path == nil) || got != want {
+			t.Fatalf("%d -> %d: %d, dijkstra %d", src, dst, got, want)
+		}
+		if path == nil {
+			continue
+		}
+		if path[0] != src || path[len(path)-1] != dst {
+			t.Fatalf("%d -> %d: path runs %d -> %d", src, dst, path[0], path[len(path)-1])
+		}
+		total := 0
+		for i := 0; i+1 < len(path); i++ {
+			out, _ := g.Neighbors(path[i])
+			best := -1
+			for _, e := range out {
+				if c := o.metric.Cost(e.DistM, e.Speed); e.Dst == path[i+1] && (best < 0 || c < best) {
+					best = c
+				}
+			}
+			if best < 0 {
+				t.Fatalf("%d -> %d: no edge %d -> %d on the path", src, dst, path[i], path[i+1])
+			}
+			total += best
+		}
+		if total != want {
+			t.Fatalf("%d -> %d: unpacked path costs %d, want %d", src, dst, total, want)
+		}
+	}
+}
+
+func TestOverlayMatchesDijkstra(t *testing.T) {
+	for _, n := range []int{5, 30, 60} {
+		g := testGrid(n, int64(n))
+		part := NewPartition(g)
+		for _, m := range []algo.Metric{algo.Distance, algo.TravelTime} {
+			checkOverlay(t, Customize(g, part, m), g, rand.New(rand.NewSource(3)))
+		}
+	}
+}
@@ -0,0 +1,106 @@
+// Package crp implements customizable route planning: a metric-independent
+// multilevel partition of the road graph, an overlay of shortcut costs
+// between the boundary nodes of every cell, and a query that climbs the
+// overlay. Edge updates only re-customize the cells that hold the edge.
+package crp
+
+import (
+	"math"
+	"sort"
+
+	"github.com/atharv3903/graphion/internal/graph"
+)
+
+const (
+	// leafSize is the most nodes a level 1 cell holds.
+	leafSize = 128
+	// fanoutBits is log2 of how many cells of one level make up a cell of
+	// the next.
+	fanoutBits = 4
+)
+
+// Partition splits the nodes of a graph into nested cells by recursive
+// coordinate bisection. Level 1 cells are the leaves of the bisection; each
+// level above merges 1<<fanoutBits cells of the level below. It depends only
+// on node positions, so it survives any edge change that keeps the node
+// table.
+type Partition struct {
+	Levels int
+
+	depth   int      // bisection depth, so there are 1<<depth leaves
+	leaf    []uint32 // dense node -> level 1 cell
+	members [][]uint32
+}
+
+// NewPartition partitions the nodes of g. Nodes without a position are
+// placed as if at 0,0.
+func NewPartition(g *graph.CSR) *Partition {
+	n := g.Len()
+	depth := 0
+	for n>>depth > leafSize {
+		depth++
+	}
+	p := &Partition{
+		Levels:  max(1, (depth+fanoutBits-1)/fanoutBits),
+		depth:   depth,
+		leaf:    make([]uint32, n),
+		members: make([][]uint32, 1<<depth),
+	}
+
+	nodes := make([]uint32, n)
+	lat := make([]float64, n)
+	lon := make([]float64, n)
+	for v := range nodes {
+		nodes[v] = uint32(v)
+		c, _, _ := g.Coord(g.ID(uint32(v)))
+		lat[v], lon[v] = c.Lat, c.Lon
+	}
+	p.bisect(nodes, lat, lon, 0, 0)
+	return p
+}
+
+// bisect sorts nodes along their wider axis and splits them at the median
+// until depth is reached.
+func (p *Partition) bisect(nodes []uint32, lat, lon []float64, level int, prefix uint32) {
+	if level == p.depth {
+		for _, v := range nodes {
+			p.leaf[v] = prefix
+		}
+		p.members[prefix] = nodes
+		return
+	}
+
+	minLat, maxLat := math.Inf(1), math.Inf(-1)
+	minLon, maxLon := math.Inf(1), math.Inf(-1)
+	for _, v := range nodes {
+		minLat, maxLat = min(minLat, lat[v]), max(maxLat, lat[v])
+		minLon, maxLon = min(minLon, lon[v]), max(maxLon, lon[v])
+	}
+	key := lat
+	if (maxLon-minLon)*math.Cos((minLat+maxLat)/2*math.Pi/180) > maxLat-minLat {
+		key = lon
+	}
+	sort.Slice(nodes, func(i, j int) bool { return key[nodes[i]] < key[nodes[j]] })
+
+	mid := len(nodes) / 2
+	p.bisect(nodes[:mid], lat, lon, level+1, prefix<<1)
+	p.bisect(nodes[mid:], lat, lon, level+1, prefix<<1|1)
+}
+
+// Cell returns the cell of dense node v at level l (1..Levels).
+func (p *Partition) Cell(l int, v uint32) uint32 {
+	return p.leaf[v] >> (fanoutBits * (l - 1))
+}
+
+// Cells is the number of cells at level l.
+func (p *Partition) Cells(l int) int {
+	return len(p.members) >> (fanoutBits * (l - 1))
+}
+
+// Members returns the nodes of cell c at level l.
+func (p *Partition) Members(l int, c uint32) [][]uint32 {
+	shift := fanoutBits * (l - 1)
+	lo := c << shift
+	hi := min(int(c+1)<<shift, len(p.members))
+	return p.members[lo:hi]
+}
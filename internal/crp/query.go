@@ -0,0 +1,138 @@
+package crp
+
+// Route returns the cheapest path from src to dst by node id, its cost and
+// the number of nodes settled. Around src and dst it searches the graph
+// itself; further away it only touches the boundary nodes of the highest
+// level cells that hold neither endpoint, moving over their cliques.
+func (o *Overlay) Route(src, dst int64) ([]int64, int, int) {
+	if src == dst {
+		return []int64{src}, 0, 0
+	}
+	s, ok := o.g.Index(src)
+	if !ok {
+		return nil, 0, 0
+	}
+	t, ok := o.g.Index(dst)
+	if !ok {
+		return nil, 0, 0
+	}
+
+	// level is the highest level at which v's cell holds neither endpoint,
+	// 0 when even its level 1 cell holds one of them
+	level := func(v uint32) int {
+		for l := o.part.Levels; l >= 1; l-- {
+			c := o.part.Cell(l, v)
+			if c != o.part.Cell(l, s) && c != o.part.Cell(l, t) {
+				return l
+			}
+		}
+		return 0
+	}
+
+	type via struct {
+		from  uint32
+		level int // 0 for an edge, else the level of the clique taken
+	}
+	dist := map[uint32]int{s: 0}
+	prev := map[uint32]via{}
+	h := &minHeap{}
+	h.push(s, 0)
+	explored := 0
+
+	relax := func(v uint32, d int, how via) {
+		if old, ok := dist[v]; ok && old <= d {
+			return
+		}
+		dist[v] = d
+		prev[v] = how
+		h.push(v, d)
+	}
+
+	for h.Len() > 0 {
+		it := h.pop()
+		u := it.node
+		if it.key > dist[u] {
+			continue
+		}
+		if u == t {
+			break
+		}
+		explored++
+
+		l := level(u)
+		edges, heads := o.g.Out(u)
+		if l == 0 {
+			for k, e := range edges {
+				relax(heads[k], it.key+o.metric.Cost(e.DistM, e.Speed), via{from: u})
+			}
+			continue
+		}
+
+		c := o.part.Cell(l, u)
+		cd := o.cells[l-1][c]
+		if i, ok := cd.entryIdx[u]; ok {
+			for j, x := range cd.exits {
+				if d := cd.clique(i, j); d != unreachable {
+					relax(x, it.key+d, via{from: u, level: l})
+				}
+			}
+		}
+		if _, ok := cd.exitIdx[u]; ok {
+			for k, e := range edges {
+				if o.part.Cell(l, heads[k]) != c {
+					relax(heads[k], it.key+o.metric.Cost(e.DistM, e.Speed), via{from: u})
+				}
+			}
+		}
+	}
+
+	if _, ok := dist[t]; !ok {
+		return nil, 0, explored
+	}
+
+	type arc struct {
+		from, to uint32
+		level    int
+	}
+	var arcs []arc
+	for v := t; v != s; v = prev[v].from {
+		arcs = append(arcs, arc{from: prev[v].from, to: v, level: prev[v].level})
+	}
+
+	path := []int64{src}
+	for i := len(arcs) - 1; i >= 0; i-- {
+		if arcs[i].level == 0 {
+			path = append(path, o.g.ID(arcs[i].to))
+			continue
+		}
+		for _, v := range o.unpack(arcs[i].level, arcs[i].from, arcs[i].to)[1:] {
+			path = append(path, o.g.ID(v))
+		}
+	}
+	return path, dist[t], explored
+}
+
+// unpack expands a clique arc from a to b at level l into the graph path
+// it stands for.
+func (o *Overlay) unpack(l int, a, b uint32) []uint32 {
+	_, prev := o.cellSearch(l, o.part.Cell(l, a), a, b, true)
+
+	type arc struct {
+		from, to uint32
+		clique   bool
+	}
+	var arcs []arc
+	for v := b; v != a; v = prev[v].from {
+		arcs = append(arcs, arc{from: prev[v].from, to: v, clique: prev[v].clique})
+	}
+
+	path := []uint32{a}
+	for i := len(arcs) - 1; i >= 0; i-- {
+		if !arcs[i].clique {
+			path = append(path, arcs[i].to)
+			continue
+		}
+		path = append(path, o.unpack(l-1, arcs[i].from, arcs[i].to)[1:]...)
+	}
+	return path
+}
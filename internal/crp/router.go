@@ -0,0 +1,40 @@
+package crp
+
+import (
+	"github.com/atharv3903/graphion/internal/algo"
+	"github.com/atharv3903/graphion/internal/graph"
+)
+
+// Name, Capabilities and Route make the Manager the algo=crp engine. The
+// overlay only answers queries on the exact graph it was customized for;
+// anything else, such as a graph with per-request exclusions, is answered
+// by Dijkstra.
+func (m *Manager) Name() string { return "crp" }
+
+func (m *Manager) Capabilities() algo.Capabilities {
+	var g *graph.CSR
+	if m.Graph != nil {
+		g = m.Graph()
+	} else {
+		m.mu.Lock()
+		g = m.latest
+		m.mu.Unlock()
+	}
+	_, ready := m.Current(g)
+	return algo.Capabilities{
+		Metrics:       []string{m.Metric.Name},
+		Profiles:      []string{algo.DefaultProfile.Name},
+		Preprocessing: true,
+		Ready:         ready,
+	}
+}
+
+func (m *Manager) Route(g algo.Graph, q algo.Query) (algo.Result, error) {
+	o, ok := m.Current(g)
+	if !ok {
+		path, total, explored, err := algo.Dijkstra(g, q.Src, q.Dst, q.Metric.Cost)
+		return algo.Result{Path: path, Total: total, Explored: explored}, err
+	}
+	path, total, explored := o.Route(q.Src, q.Dst)
+	return algo.Result{Path: path, Total: total, Explored: explored}, nil
+}
@@ -0,0 +1,123 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+)
+
+// CHArc is one persisted arc of a contraction hierarchy. Child1/Child2 are
+// arc ids of the two halves of a shortcut, or -1 for an original edge.
+type CHArc struct {
+	Src    int64
+	Dst    int64
+	Cost   int
+	Child1 int32
+	Child2 int32
+}
+
+const chBatch = 1000
+
+// SaveHierarchy replaces the stored hierarchy for metric. order lists node
+// ids by contraction rank; arc ids are positions in arcs. edges is the
+// fingerprint of the edges it was built from.
+func (s Store) SaveHierarchy(metric string, edges uint64, order []int64, arcs []CHArc) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM ch_arcs WHERE metric=?`, metric); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM ch_nodes WHERE metric=?`, metric); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`REPLACE INTO ch_meta (metric, edges) VALUES (?, ?)`, metric, edges); err != nil {
+		return err
+	}
+
+	for lo := 0; lo < len(order); lo += chBatch {
+		hi := min(lo+chBatch, len(order))
+		args := make([]any, 0, 3*(hi-lo))
+		for r := lo; r < hi; r++ {
+			args = append(args, metric, r, order[r])
+		}
+		q := `INSERT INTO ch_nodes (metric, node_rank, node_id) VALUES ` + placeholders(hi-lo, 3)
+		if _, err := tx.Exec(q, args...); err != nil {
+			return err
+		}
+	}
+
+	for lo := 0; lo < len(arcs); lo += chBatch {
+		hi := min(lo+chBatch, len(arcs))
+		args := make([]any, 0, 7*(hi-lo))
+		for i := lo; i < hi; i++ {
+			a := arcs[i]
+			args = append(args, metric, i, a.Src, a.Dst, a.Cost, a.Child1, a.Child2)
+		}
+		q := `INSERT INTO ch_arcs (metric, arc_id, src_node, dst_node, cost, child1, child2) VALUES ` + placeholders(hi-lo, 7)
+		if _, err := tx.Exec(q, args...); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// LoadHierarchy reads back what SaveHierarchy wrote. An empty order means
+// nothing is stored for metric.
+func (s Store) LoadHierarchy(metric string) (edges uint64, order []int64, arcs []CHArc, err error) {
+	err = s.DB.QueryRow(`SELECT edges FROM ch_meta WHERE metric=?`, metric).Scan(&edges)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil, nil, nil
+	}
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	rows, err := s.DB.Query(`SELECT node_id FROM ch_nodes WHERE metric=? ORDER BY node_rank`, metric)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, nil, nil, err
+		}
+		order = append(order, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, nil, nil, err
+	}
+
+	rows, err = s.DB.Query(`
+        SELECT src_node, dst_node, cost, child1, child2
+        FROM ch_arcs
+        WHERE metric=?
+        ORDER BY arc_id
+    `, metric)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var a CHArc
+		if err := rows.Scan(&a.Src, &a.Dst, &a.Cost, &a.Child1, &a.Child2); err != nil {
+			return 0, nil, nil, err
+		}
+		arcs = append(arcs, a)
+	}
+
+	return edges, order, arcs, rows.Err()
+}
+
+// placeholders renders n value tuples of width columns: (?,?),(?,?)...
+func placeholders(n, width int) string {
+	tuple := "(" + strings.TrimSuffix(strings.Repeat("?,", width), ",") + ")"
+	return strings.TrimSuffix(strings.Repeat(tuple+",", n), ",")
+}
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"github.com/atharv3903/graphion/internal/model"
 )
@@ -9,9 +10,13 @@ type Store struct {
 	DB *sql.DB
 }
 
-func (s Store) Outgoing(src int64) ([]model.Edge, error) {
-	rows, err := s.DB.Query(`
-        SELECT dst_node, distance_m, speed_kmph, closed 
+// Outgoing returns the open edges leaving src. The query is abandoned when
+// ctx is done.
+func (s Store) Outgoing(ctx context.Context, src int64) ([]model.Edge, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+        SELECT edge_id, dst_node, distance_m, speed_kmph, closed,
+               highway, access_allow, access_deny, against_oneway,
+               max_height, max_weight, max_width, no_hazmat, traffic_profile
         FROM edges 
         WHERE src_node=?
     `, src)
@@ -23,26 +28,184 @@ func (s Store) Outgoing(src int64) ([]model.Edge, error) {
 	edges := make([]model.Edge, 0, 8)
 
 	for rows.Next() {
-		var dst int64
-		var dist, speed int
+		e := model.Edge{Src: src}
+		var closed bool
+
+		if err := rows.Scan(&e.ID, &e.Dst, &e.DistM, &e.Speed, &closed,
+			&e.Highway, &e.Allow, &e.Deny, &e.AgainstOneway,
+			&e.MaxHeight, &e.MaxWeight, &e.MaxWidth, &e.NoHazmat, &e.Traffic); err != nil {
+			return nil, err
+		}
+		if closed {
+			continue
+		}
+
+		edges = append(edges, e)
+	}
+
+	return edges, rows.Err()
+}
+
+// AllEdges loads every open edge, for building whole-graph structures such
+// as contraction hierarchies.
+func (s Store) AllEdges() ([]model.Edge, error) {
+	rows, err := s.DB.Query(`
+        SELECT edge_id, src_node, dst_node, distance_m, speed_kmph,
+               highway, access_allow, access_deny, against_oneway,
+               max_height, max_weight, max_width, no_hazmat, traffic_profile
+        FROM edges
+        WHERE closed=0
+    `)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var edges []model.Edge
+
+	for rows.Next() {
+		var e model.Edge
+		if err := rows.Scan(&e.ID, &e.Src, &e.Dst, &e.DistM, &e.Speed,
+			&e.Highway, &e.Allow, &e.Deny, &e.AgainstOneway,
+			&e.MaxHeight, &e.MaxWeight, &e.MaxWidth, &e.NoHazmat, &e.Traffic); err != nil {
+			return nil, err
+		}
+		edges = append(edges, e)
+	}
+
+	return edges, rows.Err()
+}
+
+// AllNodes loads every node with its position.
+func (s Store) AllNodes() ([]model.Node, error) {
+	rows, err := s.DB.Query(`SELECT node_id, lat, lon FROM nodes`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var nodes []model.Node
+
+	for rows.Next() {
+		var n model.Node
+		if err := rows.Scan(&n.ID, &n.Coord.Lat, &n.Coord.Lon); err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, n)
+	}
+
+	return nodes, rows.Err()
+}
+
+// Segments returns the node pairs joined by an edge, closed ones included,
+// each once with the lower node id first.
+func (s Store) Segments() ([][2]int64, error) {
+	rows, err := s.DB.Query(`
+        SELECT DISTINCT LEAST(src_node, dst_node), GREATEST(src_node, dst_node)
+        FROM edges
+    `)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var segs [][2]int64
+
+	for rows.Next() {
+		var seg [2]int64
+		if err := rows.Scan(&seg[0], &seg[1]); err != nil {
+			return nil, err
+		}
+		segs = append(segs, seg)
+	}
+
+	return segs, rows.Err()
+}
+
+// MaxSpeed is the highest speed on any open edge, 0 for an empty graph.
+func (s Store) MaxSpeed() (int, error) {
+	var v int
+	err := s.DB.QueryRow(`SELECT COALESCE(MAX(speed_kmph), 0) FROM edges WHERE closed=0`).Scan(&v)
+	return v, err
+}
+
+// Lengths returns every edge, closed ones included, with only its ends and
+// length filled in.
+func (s Store) Lengths() ([]model.Edge, error) {
+	rows, err := s.DB.Query(`SELECT src_node, dst_node, distance_m FROM edges`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var edges []model.Edge
+
+	for rows.Next() {
+		var e model.Edge
+		if err := rows.Scan(&e.Src, &e.Dst, &e.DistM); err != nil {
+			return nil, err
+		}
+		edges = append(edges, e)
+	}
+
+	return edges, rows.Err()
+}
+
+// Incoming returns the open edges ending at dst, in their original
+// orientation (Src is the neighbour, Dst is dst). Served by ix_dst.
+func (s Store) Incoming(ctx context.Context, dst int64) ([]model.Edge, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+        SELECT edge_id, src_node, distance_m, speed_kmph, closed,
+               highway, access_allow, access_deny, against_oneway,
+               max_height, max_weight, max_width, no_hazmat, traffic_profile
+        FROM edges
+        WHERE dst_node=?
+    `, dst)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	edges := make([]model.Edge, 0, 8)
+
+	for rows.Next() {
+		e := model.Edge{Dst: dst}
 		var closed bool
 
-		if err := rows.Scan(&dst, &dist, &speed, &closed); err != nil {
+		if err := rows.Scan(&e.ID, &e.Src, &e.DistM, &e.Speed, &closed,
+			&e.Highway, &e.Allow, &e.Deny, &e.AgainstOneway,
+			&e.MaxHeight, &e.MaxWeight, &e.MaxWidth, &e.NoHazmat, &e.Traffic); err != nil {
 			return nil, err
 		}
 		if closed {
 			continue
 		}
 
-		edges = append(edges, model.Edge{
-			Src:   src,
-			Dst:   dst,
-			DistM: dist,
-			Speed: speed,
-		})
+		edges = append(edges, e)
 	}
 
-	return edges, nil
+	return edges, rows.Err()
+}
+
+// Edge loads one edge by id, whether or not it is closed.
+func (s Store) Edge(edgeID int64) (model.Edge, error) {
+	e := model.Edge{ID: edgeID}
+	err := s.DB.QueryRow(`
+        SELECT src_node, dst_node, distance_m, speed_kmph,
+               highway, access_allow, access_deny, against_oneway,
+               max_height, max_weight, max_width, no_hazmat, traffic_profile
+        FROM edges
+        WHERE edge_id=?
+    `, edgeID).Scan(&e.Src, &e.Dst, &e.DistM, &e.Speed,
+		&e.Highway, &e.Allow, &e.Deny, &e.AgainstOneway,
+		&e.MaxHeight, &e.MaxWeight, &e.MaxWidth, &e.NoHazmat, &e.Traffic)
+	return e, err
+}
+
+// EdgeEndpoints returns the tail and head node of an edge.
+func (s Store) EdgeEndpoints(edgeID int64) (src, dst int64, err error) {
+	err = s.DB.QueryRow(`SELECT src_node, dst_node FROM edges WHERE edge_id=?`, edgeID).Scan(&src, &dst)
+	return src, dst, err
 }
 
 // func (s Store) UpdateEdgeSpeed(edgeID int64, speed int) error {
@@ -57,38 +220,53 @@ func (s Store) Outgoing(src int64) ([]model.Edge, error) {
 
 
 // UpdateEdgeSpeed does a SELECT ... FOR UPDATE then UPDATE to create row locking.
-func (s Store) UpdateEdgeSpeed(edgeID int64, speed int) error {
+// It returns the speed the edge had before.
+func (s Store) UpdateEdgeSpeed(edgeID int64, speed int) (int, error) {
 	tx, err := s.DB.Begin()
 	if err != nil {
-		return err
+		return 0, err
 	}
 	// lock row
 	var existing int
 	if err := tx.QueryRow(`SELECT speed_kmph FROM edges WHERE edge_id=? FOR UPDATE`, edgeID).Scan(&existing); err != nil {
 		tx.Rollback()
-		return err
+		return 0, err
 	}
 	if _, err := tx.Exec(`UPDATE edges SET speed_kmph=? WHERE edge_id=?`, speed, edgeID); err != nil {
 		tx.Rollback()
-		return err
+		return 0, err
 	}
-	return tx.Commit()
+	return existing, tx.Commit()
 }
 
 // UpdateEdgeClosed does SELECT ... FOR UPDATE then UPDATE to create row locking.
-func (s Store) UpdateEdgeClosed(edgeID int64, closed bool) error {
+// It returns whether the edge was closed before.
+func (s Store) UpdateEdgeClosed(edgeID int64, closed bool) (bool, error) {
 	tx, err := s.DB.Begin()
 	if err != nil {
-		return err
+		return false, err
 	}
-	var existing int
+	var existing bool
 	if err := tx.QueryRow(`SELECT closed FROM edges WHERE edge_id=? FOR UPDATE`, edgeID).Scan(&existing); err != nil {
 		tx.Rollback()
-		return err
+		return false, err
 	}
 	if _, err := tx.Exec(`UPDATE edges SET closed=? WHERE edge_id=?`, closed, edgeID); err != nil {
 		tx.Rollback()
-		return err
+		return false, err
 	}
-	return tx.Commit()
-}
\ No newline at end of file
+	return existing, tx.Commit()
+}
+
+// NodeCoord looks up the position of a node. ok is false when the node is
+// not in the nodes table.
+func (s Store) NodeCoord(id int64) (c model.Coord, ok bool, err error) {
+	err = s.DB.QueryRow(`SELECT lat, lon FROM nodes WHERE node_id=?`, id).Scan(&c.Lat, &c.Lon)
+	if err == sql.ErrNoRows {
+		return model.Coord{}, false, nil
+	}
+	if err != nil {
+		return model.Coord{}, false, err
+	}
+	return c, true, nil
+}
@@ -0,0 +1,34 @@
+package db
+
+// TrafficProfiles loads traffic_profiles as profile id -> speed in km/h per
+// bucket, with 0 for buckets that have no row. Rows outside 0..buckets-1
+// are ignored.
+func (s Store) TrafficProfiles(buckets int) (map[int32][]int, error) {
+	rows, err := s.DB.Query(`
+        SELECT profile_id, bucket, speed_kmph
+        FROM traffic_profiles
+    `)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := map[int32][]int{}
+
+	for rows.Next() {
+		var id int32
+		var bucket, speed int
+		if err := rows.Scan(&id, &bucket, &speed); err != nil {
+			return nil, err
+		}
+		if bucket < 0 || bucket >= buckets {
+			continue
+		}
+		if out[id] == nil {
+			out[id] = make([]int, buckets)
+		}
+		out[id][bucket] = speed
+	}
+
+	return out, rows.Err()
+}
@@ -0,0 +1,27 @@
+package db
+
+import "github.com/atharv3903/graphion/internal/model"
+
+// TurnRestrictions loads every row of turn_restrictions.
+func (s Store) TurnRestrictions() ([]model.TurnRestriction, error) {
+	rows, err := s.DB.Query(`
+        SELECT from_edge, via_node, to_edge, type
+        FROM turn_restrictions
+    `)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []model.TurnRestriction
+
+	for rows.Next() {
+		var r model.TurnRestriction
+		if err := rows.Scan(&r.FromEdge, &r.Via, &r.ToEdge, &r.Type); err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+
+	return out, rows.Err()
+}
@@ -0,0 +1,213 @@
+package graph
+
+import (
+	"slices"
+
+	"github.com/atharv3903/graphion/internal/db"
+	"github.com/atharv3903/graphion/internal/model"
+)
+
+// CSR is an in-memory copy of the open road graph in compressed sparse row
+// form. OSM node ids are remapped to dense indices 0..Len()-1; the edges
+// leaving node v are edges[off[v]:off[v+1]], with the dense index of each
+// edge's head alongside in heads. Incoming edges are laid out the same way.
+//
+// A CSR is never modified after construction, so any number of searches
+// can read it concurrently. Changes produce a new CSR (see WithEdges) with
+// a higher Version.
+type CSR struct {
+	version uint64
+
+	ids      []int64
+	index    map[int64]uint32
+	coords   []model.Coord
+	hasCoord []bool
+
+	off   []uint32
+	edges []model.Edge
+	heads []uint32
+
+	roff   []uint32
+	redges []model.Edge
+	rtails []uint32
+}
+
+// Load reads all nodes and open edges from store.
+func Load(store db.Store) (*CSR, error) {
+	nodes, err := store.AllNodes()
+	if err != nil {
+		return nil, err
+	}
+	edges, err := store.AllEdges()
+	if err != nil {
+		return nil, err
+	}
+	return New(nodes, edges), nil
+}
+
+// New builds a CSR. Edge endpoints missing from nodes are added without
+// coordinates.
+func New(nodes []model.Node, edges []model.Edge) *CSR {
+	g := &CSR{version: 1, index: make(map[int64]uint32, len(nodes))}
+	for _, n := range nodes {
+		v := g.node(n.ID)
+		g.coords[v] = n.Coord
+		g.hasCoord[v] = true
+	}
+
+	tails := make([]uint32, len(edges))
+	heads := make([]uint32, len(edges))
+	for i, e := range edges {
+		tails[i] = g.node(e.Src)
+		heads[i] = g.node(e.Dst)
+	}
+
+	g.off, g.edges, g.heads = bucket(len(g.ids), edges, tails, heads)
+	g.roff, g.redges, g.rtails = bucket(len(g.ids), edges, heads, tails)
+	return g
+}
+
+func (g *CSR) node(id int64) uint32 {
+	if v, ok := g.index[id]; ok {
+		return v
+	}
+	v := uint32(len(g.ids))
+	g.index[id] = v
+	g.ids = append(g.ids, id)
+	g.coords = append(g.coords, model.Coord{})
+	g.hasCoord = append(g.hasCoord, false)
+	return v
+}
+
+// bucket groups edges by key (counting sort) and returns the row offsets,
+// the grouped edges and other[] in the same order.
+func bucket(n int, edges []model.Edge, key, other []uint32) ([]uint32, []model.Edge, []uint32) {
+	off := make([]uint32, n+1)
+	for _, k := range key {
+		off[k+1]++
+	}
+	for v := 0; v < n; v++ {
+		off[v+1] += off[v]
+	}
+
+	next := make([]uint32, n)
+	copy(next, off[:n])
+	grouped := make([]model.Edge, len(edges))
+	ends := make([]uint32, len(edges))
+	for i, e := range edges {
+		k := key[i]
+		grouped[next[k]] = e
+		ends[next[k]] = other[i]
+		next[k]++
+	}
+	return off, grouped, ends
+}
+
+// WithEdges returns a copy of g whose edge set is fn applied to a copy of
+// g's edges. The node table is shared with g, so this costs one pass over
+// the edges rather than a reload. g itself is left untouched.
+func (g *CSR) WithEdges(fn func([]model.Edge) []model.Edge) *CSR {
+	edges := fn(slices.Clone(g.edges))
+
+	for _, e := range edges {
+		_, srcOK := g.index[e.Src]
+		_, dstOK := g.index[e.Dst]
+		if !srcOK || !dstOK {
+			// new endpoints, which the shared node table can't take
+			return g.rebuild(edges)
+		}
+	}
+
+	next := *g
+	next.version = g.version + 1
+
+	tails := make([]uint32, len(edges))
+	heads := make([]uint32, len(edges))
+	for i, e := range edges {
+		tails[i] = g.index[e.Src]
+		heads[i] = g.index[e.Dst]
+	}
+	next.off, next.edges, next.heads = bucket(len(g.ids), edges, tails, heads)
+	next.roff, next.redges, next.rtails = bucket(len(g.ids), edges, heads, tails)
+	return &next
+}
+
+func (g *CSR) rebuild(edges []model.Edge) *CSR {
+	next := New(g.AllNodes(), edges)
+	next.version = g.version + 1
+	return next
+}
+
+// SharesNodes reports whether g and o number their nodes the same way,
+// which holds for CSRs derived from one another by WithEdges unless new
+// endpoints forced a rebuild.
+func (g *CSR) SharesNodes(o *CSR) bool {
+	return len(g.ids) == len(o.ids) && (len(g.ids) == 0 || &g.ids[0] == &o.ids[0])
+}
+
+// Version increases with every WithEdges.
+func (g *CSR) Version() uint64 { return g.version }
+
+func (g *CSR) Len() int { return len(g.ids) }
+
+// Edges is the number of directed edges.
+func (g *CSR) Edges() int { return len(g.edges) }
+
+// AllEdges returns a copy of every edge.
+func (g *CSR) AllEdges() []model.Edge { return slices.Clone(g.edges) }
+
+// AllNodes returns the nodes that have coordinates.
+func (g *CSR) AllNodes() []model.Node {
+	var nodes []model.Node
+	for v, id := range g.ids {
+		if g.hasCoord[v] {
+			nodes = append(nodes, model.Node{ID: id, Coord: g.coords[v]})
+		}
+	}
+	return nodes
+}
+
+func (g *CSR) Index(id int64) (uint32, bool) {
+	v, ok := g.index[id]
+	return v, ok
+}
+
+func (g *CSR) ID(v uint32) int64 { return g.ids[v] }
+
+func (g *CSR) Out(v uint32) ([]model.Edge, []uint32) {
+	lo, hi := g.off[v], g.off[v+1]
+	return g.edges[lo:hi:hi], g.heads[lo:hi:hi]
+}
+
+func (g *CSR) In(v uint32) ([]model.Edge, []uint32) {
+	lo, hi := g.roff[v], g.roff[v+1]
+	return g.redges[lo:hi:hi], g.rtails[lo:hi:hi]
+}
+
+// Neighbors, InNeighbors and Coord make a CSR an algo.Graph. Unknown nodes
+// have no edges.
+func (g *CSR) Neighbors(n int64) ([]model.Edge, error) {
+	v, ok := g.index[n]
+	if !ok {
+		return nil, nil
+	}
+	edges, _ := g.Out(v)
+	return edges, nil
+}
+
+func (g *CSR) InNeighbors(n int64) ([]model.Edge, error) {
+	v, ok := g.index[n]
+	if !ok {
+		return nil, nil
+	}
+	edges, _ := g.In(v)
+	return edges, nil
+}
+
+func (g *CSR) Coord(n int64) (model.Coord, bool, error) {
+	v, ok := g.index[n]
+	if !ok || !g.hasCoord[v] {
+		return model.Coord{}, false, nil
+	}
+	return g.coords[v], true, nil
+}
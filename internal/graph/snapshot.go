@@ -0,0 +1,40 @@
+package graph
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/atharv3903/graphion/internal/model"
+)
+
+// Snapshots publishes successive versions of an in-memory graph. Readers
+// Load the current CSR once and use it for their whole search; since a CSR
+// is immutable they see a consistent graph no matter what is published in
+// the meantime. Writers derive the next version from the current one and
+// swap it in atomically, so readers never wait on writers.
+type Snapshots struct {
+	cur atomic.Pointer[CSR]
+	mu  sync.Mutex // serialises writers
+}
+
+func NewSnapshots(g *CSR) *Snapshots {
+	s := &Snapshots{}
+	s.cur.Store(g)
+	return s
+}
+
+// Load returns the current graph.
+func (s *Snapshots) Load() *CSR {
+	return s.cur.Load()
+}
+
+// Update publishes the current graph's edges transformed by fn and returns
+// the new version. Concurrent updates are applied one after the other.
+func (s *Snapshots) Update(fn func([]model.Edge) []model.Edge) *CSR {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	next := s.cur.Load().WithEdges(fn)
+	s.cur.Store(next)
+	return next
+}
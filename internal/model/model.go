@@ -1,15 +1,246 @@
 package model
 
+import "time"
+
 type Edge struct {
+	ID    int64 // edges.edge_id
 	Src   int64
 	Dst   int64
 	DistM int
 	Speed int
+
+	Highway string // OSM highway class, "" when unknown
+	// Allow and Deny are the modes the OSM access tags explicitly permit
+	// or forbid here; they override a profile's road classes.
+	Allow, Deny Modes
+	// AgainstOneway marks an edge running against a oneway road, usable
+	// only by profiles that ignore oneways or modes in Allow.
+	AgainstOneway bool
+
+	// legal limits from OSM, 0 where there is none
+	MaxHeight float64 // meters
+	MaxWeight float64 // tonnes
+	MaxWidth  float64 // meters
+	NoHazmat  bool    // hazardous goods forbidden
+
+	// Traffic is the speed profile (traffic_profiles.profile_id) giving
+	// the edge's speed by time of week, 0 for none.
+	Traffic int32
+}
+
+// Vehicle is what a request says about the vehicle's size and load, 0
+// where not given.
+type Vehicle struct {
+	Height float64 // meters
+	Weight float64 // tonnes
+	Width  float64 // meters
+	Hazmat bool
+}
+
+// BlockedEdge is an edge a vehicle may not use, and the limit it breaks.
+type BlockedEdge struct {
+	EdgeID      int64   `json:"edge_id"`
+	Restriction string  `json:"restriction"` // maxheight, maxweight, maxwidth or hazmat
+	Limit       float64 `json:"limit,omitempty"`
+}
+
+// Modes is a set of travel modes.
+type Modes uint8
+
+const (
+	ModeCar Modes = 1 << iota
+	ModeBicycle
+	ModeFoot
+	ModeTruck
+)
+
+// Coord is a node position in WGS84 degrees, as stored in the nodes table.
+type Coord struct {
+	Lat float64
+	Lon float64
+}
+
+type Node struct {
+	ID    int64
+	Coord Coord
+}
+
+// TurnRestriction is an OSM restriction on turning from one edge onto
+// another at the node they share. Type is the OSM restriction value:
+// "no_*" bans the turn, "only_*" bans every other turn off FromEdge at Via.
+type TurnRestriction struct {
+	FromEdge int64
+	Via      int64
+	ToEdge   int64
+	Type     string
 }
 
 type RouteResponse struct {
 	Path          []int64 `json:"path"`
-	Total         int     `json:"total"`
+	Total         int     `json:"total"` // in the metric's unit
+	Metric        string  `json:"metric"`
+	TotalMeters   int     `json:"total_meters"`
+	TotalSeconds  float64 `json:"total_seconds"`
 	ExploredNodes int     `json:"explored_nodes"`
 	CacheHit      bool    `json:"cache_hit"`
+	// Error is set when the search was stopped before it finished:
+	// explore_budget_exceeded, deadline_exceeded or canceled.
+	Error string `json:"error,omitempty"`
+	// DepartAt and ETA are set for /route?depart_at= and arrive_by=, whose
+	// totals then follow the traffic at the time each edge is reached. For
+	// arrive_by DepartAt is the latest departure that makes it, and ETA is
+	// no later than ArriveBy.
+	DepartAt *time.Time `json:"depart_at,omitempty"`
+	ETA      *time.Time `json:"eta,omitempty"`
+	ArriveBy *time.Time `json:"arrive_by,omitempty"`
+	// From and To are where /route?from=&to= coordinates were snapped, and
+	// Geometry the route's GeoJSON LineString between them.
+	From     *Snap     `json:"from,omitempty"`
+	To       *Snap     `json:"to,omitempty"`
+	Geometry *Geometry `json:"geometry,omitempty"`
+
+	Alternatives []AlternativeRoute `json:"alternatives,omitempty"`
+	// Blocked is set when there is no route for the vehicle given: the
+	// restricted edges on the route it would otherwise have taken.
+	Blocked []BlockedEdge `json:"blocked,omitempty"`
+	// Legs are the waypoint to waypoint routes of /route?points=, which
+	// Path and the totals concatenate.
+	Legs []RouteResponse `json:"legs,omitempty"`
+}
+
+// Snap is where a query coordinate was moved to, and how far it moved in
+// meters: a graph node, or a point on the road Segment between two nodes,
+// Fraction of the way from the first to the second.
+type Snap struct {
+	Node      int64   `json:"node,omitempty"`
+	Segment   []int64 `json:"segment,omitempty"`
+	Fraction  float64 `json:"fraction,omitempty"`
+	Lat       float64 `json:"lat"`
+	Lon       float64 `json:"lon"`
+	DistanceM float64 `json:"distance_m"`
+}
+
+// AlternativeRoute is one of the /route?alternatives=N routes. Shared is
+// the fraction of the primary path's cost it also travels.
+type AlternativeRoute struct {
+	RouteResponse
+	Shared float64 `json:"shared"`
+}
+
+// MatrixRequest is the body of POST /matrix.
+type MatrixRequest struct {
+	Sources []int64 `json:"sources"`
+	Targets []int64 `json:"targets"`
+	Metric  string  `json:"metric"`
+	Profile string  `json:"profile"`
+}
+
+// MatrixResponse holds Costs[i][j] from Sources[i] to Targets[j] in the
+// metric's unit, null where there is no path.
+type MatrixResponse struct {
+	Costs         [][]*int `json:"costs"`
+	Metric        string   `json:"metric"`
+	ExploredNodes int      `json:"explored_nodes"`
+}
+
+// TripRequest is the body of POST /trip. The trip starts at Stops[0]; it
+// returns there with RoundTrip, ends at the last stop with FixedEnd, and
+// ends wherever is cheapest otherwise.
+type TripRequest struct {
+	Stops     []int64 `json:"stops"`
+	Metric    string  `json:"metric"`
+	Profile   string  `json:"profile"`
+	Algo      string  `json:"algo"`
+	RoundTrip bool    `json:"round_trip"`
+	FixedEnd  bool    `json:"fixed_end"`
+}
+
+// TripResponse is the route through the stops in the order chosen, one leg
+// per hop.
+type TripResponse struct {
+	Order []int64 `json:"order"`
+	RouteResponse
+}
+
+// VRPRequest is the body of POST /vrp. Times are seconds from the start of
+// the plan; travel times come from the time metric.
+type VRPRequest struct {
+	Depot     int64        `json:"depot"`
+	Vehicles  []VRPVehicle `json:"vehicles"`
+	Jobs      []VRPJob     `json:"jobs"`
+	Profile   string       `json:"profile"`
+	TimeLimit float64      `json:"time_limit"` // solver budget in seconds, 0 for the default
+}
+
+type VRPVehicle struct {
+	ID         string  `json:"id"`
+	Capacity   int     `json:"capacity"`
+	ShiftStart float64 `json:"shift_start"`
+	ShiftEnd   float64 `json:"shift_end"` // 0 for no limit
+}
+
+type VRPJob struct {
+	ID       string  `json:"id"`
+	Node     int64   `json:"node"`
+	Demand   int     `json:"demand"`
+	Earliest float64 `json:"earliest"`
+	Latest   float64 `json:"latest"` // 0 for no limit
+	Service  float64 `json:"service"`
+}
+
+// VRPStatus is what GET /vrp/{id} returns. Status is queued, running, done
+// or failed; Result is set once done.
+type VRPStatus struct {
+	ID     string     `json:"id"`
+	Status string     `json:"status"`
+	Error  string     `json:"error,omitempty"`
+	Result *VRPResult `json:"result,omitempty"`
+}
+
+type VRPResult struct {
+	Routes        []VRPRoute `json:"routes"`
+	Unassigned    []string   `json:"unassigned"`
+	TravelSeconds float64    `json:"travel_seconds"`
+}
+
+type VRPRoute struct {
+	Vehicle       string    `json:"vehicle"`
+	Stops         []VRPStop `json:"stops"`
+	Load          int       `json:"load"`
+	TravelSeconds float64   `json:"travel_seconds"`
+	ReturnAt      float64   `json:"return_at"`
+}
+
+type VRPStop struct {
+	Job       string  `json:"job"`
+	Node      int64   `json:"node"`
+	Arrival   float64 `json:"arrival"`
+	Departure float64 `json:"departure"`
+}
+
+// FeatureCollection, Feature and Geometry are the parts of GeoJSON
+// (RFC 7946) the API returns. Positions are [lon, lat].
+type FeatureCollection struct {
+	Type     string    `json:"type"` // "FeatureCollection"
+	Features []Feature `json:"features"`
+}
+
+type Feature struct {
+	Type       string         `json:"type"` // "Feature"
+	Geometry   Geometry       `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+type Geometry struct {
+	Type        string `json:"type"`
+	Coordinates any    `json:"coordinates"`
+}
+
+// KRoutesResponse is the answer to /routes/k: the k cheapest loopless
+// paths, cheapest first. ExploredNodes is summed over all searches.
+type KRoutesResponse struct {
+	Routes        []RouteResponse `json:"routes"`
+	Metric        string          `json:"metric"`
+	ExploredNodes int             `json:"explored_nodes"`
+	CacheHit      bool            `json:"cache_hit"`
 }
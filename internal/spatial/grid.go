@@ -0,0 +1,109 @@
+// Package spatial finds graph nodes and road segments near a coordinate.
+package spatial
+
+import (
+	"math"
+
+	"github.com/atharv3903/graphion/internal/algo"
+	"github.com/atharv3903/graphion/internal/model"
+)
+
+// metersPerDegree is the length of one degree of latitude.
+const metersPerDegree = 111320.0
+
+type cell struct{ lat, lon int32 }
+
+// cellSize is a cell's size in degrees, shared by the node and segment
+// grids.
+type cellSize struct{ lat, lon float64 }
+
+// newCellSize makes cells about cellM meters on a side at latitude lat.
+func newCellSize(cellM, lat float64) cellSize {
+	return cellSize{
+		lat: cellM / metersPerDegree,
+		lon: cellM / (metersPerDegree * max(math.Cos(lat*math.Pi/180), 0.01)),
+	}
+}
+
+func (cs cellSize) cellOf(c model.Coord) cell {
+	return cell{int32(math.Floor(c.Lat / cs.lat)), int32(math.Floor(c.Lon / cs.lon))}
+}
+
+// rings visits the cells around c ring by ring, nearest first, until a ring
+// can hold nothing within maxM or closer than what *bestM has come down to.
+func (cs cellSize) rings(c model.Coord, maxM float64, bestM *float64, visit func(cell)) {
+	// a cell r rings out is at least (r-1) times the narrower side away
+	side := min(cs.lat, cs.lon*math.Cos(c.Lat*math.Pi/180)) * metersPerDegree
+	home := cs.cellOf(c)
+
+	for r := 0; ; r++ {
+		reach := float64(r-1) * side
+		if reach > maxM || reach >= *bestM {
+			return
+		}
+		for dlat := -r; dlat <= r; dlat++ {
+			// the ring's top and bottom rows in full, its sides at the ends
+			step := 2 * r
+			if dlat == -r || dlat == r || r == 0 {
+				step = 1
+			}
+			for dlon := -r; dlon <= r; dlon += step {
+				visit(cell{home.lat + int32(dlat), home.lon + int32(dlon)})
+			}
+		}
+	}
+}
+
+// Grid buckets nodes by position into cells of roughly equal size in
+// meters. It is never modified once built, so lookups may run
+// concurrently.
+type Grid struct {
+	cellSize
+	cells map[cell][]model.Node
+	n     int
+}
+
+// NewGrid indexes nodes in cells about cellM meters on a side. Longitude
+// is scaled at the nodes' mean latitude, which suits a city or region.
+func NewGrid(nodes []model.Node, cellM float64) *Grid {
+	lat := 0.0
+	for _, n := range nodes {
+		lat += n.Coord.Lat
+	}
+	if len(nodes) > 0 {
+		lat /= float64(len(nodes))
+	}
+
+	g := &Grid{
+		cellSize: newCellSize(cellM, lat),
+		cells:    map[cell][]model.Node{},
+		n:        len(nodes),
+	}
+	for _, n := range nodes {
+		c := g.cellOf(n.Coord)
+		g.cells[c] = append(g.cells[c], n)
+	}
+	return g
+}
+
+// Len is the number of nodes indexed.
+func (g *Grid) Len() int { return g.n }
+
+// Nearest returns the node closest to c among those keep accepts (all of
+// them for a nil keep) and its distance in meters. It looks no further than
+// maxM and reports false if nothing is that close.
+func (g *Grid) Nearest(c model.Coord, maxM float64, keep func(id int64) bool) (model.Node, float64, bool) {
+	var best model.Node
+	bestM := math.Inf(1)
+	g.rings(c, maxM, &bestM, func(k cell) {
+		for _, n := range g.cells[k] {
+			if keep != nil && !keep(n.ID) {
+				continue
+			}
+			if d := algo.Haversine(c, n.Coord); d < bestM && d <= maxM {
+				best, bestM = n, d
+			}
+		}
+	})
+	return best, bestM, !math.IsInf(bestM, 1)
+}
@@ -0,0 +1,39 @@
+package spatial
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/atharv3903/graphion/internal/algo"
+	"github.com/atharv3903/graphion/internal/model"
+)
+
+func TestGridMatchesScan(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	var nodes []model.Node
+	for i := range 3000 {
+		nodes = append(nodes, model.Node{ID: int64(i), Coord: model.Coord{Lat: 60 + rnd.Float64()*0.1, Lon: 10 + rnd.Float64()*0.2}})
+	}
+	g := NewGrid(nodes, 200)
+
+	keep := func(id int64) bool { return id%7 == 0 }
+	for k := range 1000 {
+		c := model.Coord{Lat: 59.99 + rnd.Float64()*0.12, Lon: 9.98 + rnd.Float64()*0.24}
+		maxM := []float64{100, 500, 2000}[k%3]
+		for _, kp := range []func(int64) bool{nil, keep} {
+			best := -1.0
+			for _, n := range nodes {
+				if kp != nil && !kp(n.ID) {
+					continue
+				}
+				if d := algo.Haversine(c, n.Coord); d <= maxM && (best < 0 || d < best) {
+					best = d
+				}
+			}
+			_, d, ok := g.Nearest(c, maxM, kp)
+			if ok != (best >= 0) || ok && d != best {
+				t.Fatalf("%v within %v m: grid %v (%v), scan %v", c, maxM, d, ok, best)
+			}
+		}
+	}
+}
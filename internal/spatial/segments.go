@@ -0,0 +1,98 @@
+package spatial
+
+import (
+	"math"
+
+	"github.com/atharv3903/graphion/internal/algo"
+	"github.com/atharv3903/graphion/internal/model"
+)
+
+// Segment is the straight stretch of road between two nodes.
+type Segment struct{ A, B model.Node }
+
+// Projection is the point of a segment nearest some coordinate.
+type Projection struct {
+	Segment
+	Coord model.Coord
+	// Frac is how far along the segment from A to B the point lies, 0 to 1.
+	Frac  float64
+	DistM float64
+}
+
+// SegmentGrid buckets road segments into every cell their bounding box
+// touches, so the cells around a coordinate hold every segment passing
+// through them. Like Grid it is read-only once built.
+type SegmentGrid struct {
+	cellSize
+	cells map[cell][]int32
+	segs  []Segment
+}
+
+// NewSegmentGrid indexes segs in cells about cellM meters on a side.
+func NewSegmentGrid(segs []Segment, cellM float64) *SegmentGrid {
+	lat := 0.0
+	for _, s := range segs {
+		lat += (s.A.Coord.Lat + s.B.Coord.Lat) / 2
+	}
+	if len(segs) > 0 {
+		lat /= float64(len(segs))
+	}
+
+	g := &SegmentGrid{
+		cellSize: newCellSize(cellM, lat),
+		cells:    map[cell][]int32{},
+		segs:     segs,
+	}
+	for i, s := range segs {
+		a, b := g.cellOf(s.A.Coord), g.cellOf(s.B.Coord)
+		for la := min(a.lat, b.lat); la <= max(a.lat, b.lat); la++ {
+			for lo := min(a.lon, b.lon); lo <= max(a.lon, b.lon); lo++ {
+				k := cell{la, lo}
+				g.cells[k] = append(g.cells[k], int32(i))
+			}
+		}
+	}
+	return g
+}
+
+// Len is the number of segments indexed.
+func (g *SegmentGrid) Len() int { return len(g.segs) }
+
+// Nearest projects c onto the closest segment keep accepts (any for a nil
+// keep). It looks no further than maxM and reports false if no segment is
+// that close.
+func (g *SegmentGrid) Nearest(c model.Coord, maxM float64, keep func(Segment) bool) (Projection, bool) {
+	var best Projection
+	bestM := math.Inf(1)
+	g.rings(c, maxM, &bestM, func(k cell) {
+		for _, i := range g.cells[k] {
+			s := g.segs[i]
+			if keep != nil && !keep(s) {
+				continue
+			}
+			p := project(c, s)
+			if p.DistM < bestM && p.DistM <= maxM {
+				best, bestM = p, p.DistM
+			}
+		}
+	})
+	return best, !math.IsInf(bestM, 1)
+}
+
+// project finds the point of s nearest c, treating the few degrees around
+// c as flat with longitude scaled by its latitude.
+func project(c model.Coord, s Segment) Projection {
+	k := math.Cos(c.Lat * math.Pi / 180)
+	ax, ay := (s.A.Coord.Lon-c.Lon)*k, s.A.Coord.Lat-c.Lat
+	dx, dy := (s.B.Coord.Lon-s.A.Coord.Lon)*k, s.B.Coord.Lat-s.A.Coord.Lat
+
+	t := 0.0
+	if l := dx*dx + dy*dy; l > 0 {
+		t = min(max(-(ax*dx+ay*dy)/l, 0), 1)
+	}
+	p := model.Coord{
+		Lat: s.A.Coord.Lat + t*(s.B.Coord.Lat-s.A.Coord.Lat),
+		Lon: s.A.Coord.Lon + t*(s.B.Coord.Lon-s.A.Coord.Lon),
+	}
+	return Projection{Segment: s, Coord: p, Frac: t, DistM: algo.Haversine(c, p)}
+}
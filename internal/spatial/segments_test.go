@@ -0,0 +1,49 @@
+package spatial
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/atharv3903/graphion/internal/algo"
+	"github.com/atharv3903/graphion/internal/model"
+)
+
+func TestSegmentGridMatchesScan(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	var nodes []model.Node
+	for i := range 2000 {
+		nodes = append(nodes, model.Node{ID: int64(i), Coord: model.Coord{Lat: 60 + rnd.Float64()*0.1, Lon: 10 + rnd.Float64()*0.2}})
+	}
+	var segs []Segment
+	for i := range 3000 {
+		// mostly short roads, and every fiftieth one as long as it comes
+		a := nodes[rnd.Intn(len(nodes))]
+		b := nodes[rnd.Intn(len(nodes))]
+		for i%50 != 0 && algo.Haversine(a.Coord, b.Coord) >= 800 {
+			b = nodes[rnd.Intn(len(nodes))]
+		}
+		segs = append(segs, Segment{A: a, B: b})
+	}
+	g := NewSegmentGrid(segs, 200)
+
+	keep := func(s Segment) bool { return s.A.ID%3 == 0 }
+	for k := range 1000 {
+		c := model.Coord{Lat: 59.99 + rnd.Float64()*0.12, Lon: 9.98 + rnd.Float64()*0.24}
+		maxM := []float64{50, 300, 2000}[k%3]
+		for _, kp := range []func(Segment) bool{nil, keep} {
+			best := -1.0
+			for _, s := range segs {
+				if kp != nil && !kp(s) {
+					continue
+				}
+				if p := project(c, s); p.DistM <= maxM && (best < 0 || p.DistM < best) {
+					best = p.DistM
+				}
+			}
+			p, ok := g.Nearest(c, maxM, kp)
+			if ok != (best >= 0) || ok && p.DistM != best {
+				t.Fatalf("%v within %v m: grid %v (%v), scan %v", c, maxM, p.DistM, ok, best)
+			}
+		}
+	}
+}
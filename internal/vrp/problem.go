@@ -0,0 +1,113 @@
+// Package vrp assigns jobs to capacity-limited vehicles that leave from
+// and return to one depot, honouring delivery time windows.
+package vrp
+
+// Problem is one vehicle routing instance. Times are milliseconds from the
+// start of the plan. Travel[i][j] is the driving time from location i to
+// location j, where location 0 is the depot and location i+1 is Jobs[i];
+// negative entries mark pairs with no path.
+type Problem struct {
+	Vehicles []Vehicle
+	Jobs     []Job
+	Travel   [][]int
+}
+
+// Vehicle leaves the depot no earlier than Start and must be back by End
+// (0 for no limit), carrying at most Capacity.
+type Vehicle struct {
+	ID       string
+	Capacity int
+	Start    int
+	End      int
+}
+
+// Job is a delivery of Demand units. Service starts within
+// [Earliest, Latest] (Latest 0 for no limit), waiting if the vehicle is
+// early, and takes Service.
+type Job struct {
+	ID       string
+	Demand   int
+	Earliest int
+	Latest   int
+	Service  int
+}
+
+// Stop is a job on a route with the time the vehicle arrives and leaves.
+type Stop struct {
+	Job       int
+	Arrival   int
+	Departure int
+}
+
+// Route is the work of one vehicle. Return is when it is back at the depot.
+type Route struct {
+	Vehicle int
+	Stops   []Stop
+	Load    int
+	Travel  int
+	Return  int
+}
+
+// Solution lists one route per vehicle that has any jobs, and the jobs no
+// vehicle could take. Travel is the total driving time.
+type Solution struct {
+	Routes     []Route
+	Unassigned []int
+	Travel     int
+}
+
+func (p *Problem) travel(from, to int) (int, bool) {
+	t := p.Travel[from][to]
+	return t, t >= 0
+}
+
+// schedule times vehicle v driving seq (job indices) and reports whether
+// that breaks no capacity, time window or shift limit.
+func (p *Problem) schedule(v int, seq []int) (Route, bool) {
+	veh := p.Vehicles[v]
+	r := Route{Vehicle: v, Stops: make([]Stop, 0, len(seq))}
+
+	at, loc := veh.Start, 0
+	for _, j := range seq {
+		job := p.Jobs[j]
+		r.Load += job.Demand
+		if r.Load > veh.Capacity {
+			return Route{}, false
+		}
+
+		t, ok := p.travel(loc, j+1)
+		if !ok {
+			return Route{}, false
+		}
+		r.Travel += t
+		arrival := at + t
+		start := max(arrival, job.Earliest)
+		if job.Latest > 0 && start > job.Latest {
+			return Route{}, false
+		}
+
+		at, loc = start+job.Service, j+1
+		r.Stops = append(r.Stops, Stop{Job: j, Arrival: arrival, Departure: at})
+	}
+
+	t, ok := p.travel(loc, 0)
+	if !ok {
+		return Route{}, false
+	}
+	r.Travel += t
+	r.Return = at + t
+	if veh.End > 0 && r.Return > veh.End {
+		return Route{}, false
+	}
+	return r, true
+}
+
+// cost is the driving time of seq on vehicle v, or false if infeasible.
+// An empty route costs nothing.
+func (p *Problem) cost(v int, seq []int) (int, bool) {
+	if len(seq) == 0 {
+		return 0, true
+	}
+	r, ok := p.schedule(v, seq)
+	return r.Travel, ok
+}
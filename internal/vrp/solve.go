@@ -0,0 +1,212 @@
+package vrp
+
+import (
+	"slices"
+	"time"
+)
+
+// Solve builds routes by regret insertion and then improves them by local
+// search (relocate, swap and 2-opt moves) until no move helps or limit
+// has passed. Jobs regret insertion has not placed by then are given their
+// cheapest places instead.
+func Solve(p Problem, limit time.Duration) Solution {
+	deadline := time.Now().Add(limit)
+
+	s := &solver{p: &p, routes: make([][]int, len(p.Vehicles))}
+	for j := range p.Jobs {
+		s.unassigned = append(s.unassigned, j)
+	}
+	s.insertRegret(deadline)
+
+	for improved := true; improved && time.Now().Before(deadline); {
+		improved = s.relocate(deadline) || s.swap(deadline) || s.twoOpt(deadline)
+		if s.insertRegret(deadline) {
+			improved = true
+		}
+	}
+
+	return s.solution()
+}
+
+type solver struct {
+	p          *Problem
+	routes     [][]int
+	unassigned []int
+}
+
+// insertion is job placed before position pos of route r, adding delta
+// driving time.
+type insertion struct {
+	r, pos, delta int
+}
+
+// bestInsertions returns the cheapest feasible place for job j in every
+// route that has one.
+func (s *solver) bestInsertions(j int) []insertion {
+	var out []insertion
+	for r, seq := range s.routes {
+		base, _ := s.p.cost(r, seq)
+		best := insertion{r: r, pos: -1}
+		for pos := 0; pos <= len(seq); pos++ {
+			c, ok := s.p.cost(r, slices.Insert(slices.Clone(seq), pos, j))
+			if ok && (best.pos < 0 || c-base < best.delta) {
+				best.pos, best.delta = pos, c-base
+			}
+		}
+		if best.pos >= 0 {
+			out = append(out, best)
+		}
+	}
+	return out
+}
+
+// insertRegret places unassigned jobs one at a time, always the one that
+// loses the most by not getting its best route (regret-2). Each step costs
+// a full pass over the unassigned jobs, so once deadline has passed the
+// rest go through insertCheapest. It reports whether anything was placed.
+func (s *solver) insertRegret(deadline time.Time) bool {
+	placed := false
+	for len(s.unassigned) > 0 {
+		bestIdx, bestRegret := -1, 0
+		var bestIns insertion
+
+		for idx, j := range s.unassigned {
+			if time.Now().After(deadline) {
+				return s.insertCheapest() || placed
+			}
+			ins := s.bestInsertions(j)
+			if len(ins) == 0 {
+				continue
+			}
+			slices.SortFunc(ins, func(a, b insertion) int { return a.delta - b.delta })
+			// a job with one option left has to go there first
+			regret := 1 << 40
+			if len(ins) > 1 {
+				regret = ins[1].delta - ins[0].delta
+			}
+			if bestIdx < 0 || regret > bestRegret || (regret == bestRegret && ins[0].delta < bestIns.delta) {
+				bestIdx, bestRegret, bestIns = idx, regret, ins[0]
+			}
+		}
+		if bestIdx < 0 {
+			return placed
+		}
+
+		j := s.unassigned[bestIdx]
+		s.routes[bestIns.r] = slices.Insert(s.routes[bestIns.r], bestIns.pos, j)
+		s.unassigned = slices.Delete(s.unassigned, bestIdx, bestIdx+1)
+		placed = true
+	}
+	return placed
+}
+
+// insertCheapest places the unassigned jobs in turn at their cheapest
+// feasible place, in one pass. It reports whether anything was placed.
+func (s *solver) insertCheapest() bool {
+	placed := false
+	var left []int
+	for _, j := range s.unassigned {
+		ins := s.bestInsertions(j)
+		if len(ins) == 0 {
+			left = append(left, j)
+			continue
+		}
+		best := slices.MinFunc(ins, func(a, b insertion) int { return a.delta - b.delta })
+		s.routes[best.r] = slices.Insert(s.routes[best.r], best.pos, j)
+		placed = true
+	}
+	s.unassigned = left
+	return placed
+}
+
+// relocate moves one job to the cheapest place in any route, if that
+// lowers the total. It reports whether a move was made.
+func (s *solver) relocate(deadline time.Time) bool {
+	for r := range s.routes {
+		for i := 0; i < len(s.routes[r]); i++ {
+			if time.Now().After(deadline) {
+				return false
+			}
+			j := s.routes[r][i]
+			before, _ := s.p.cost(r, s.routes[r])
+			without := slices.Delete(slices.Clone(s.routes[r]), i, i+1)
+			after, ok := s.p.cost(r, without)
+			if !ok {
+				continue
+			}
+			gain := before - after
+
+			old := s.routes[r]
+			s.routes[r] = without
+			for _, ins := range s.bestInsertions(j) {
+				if ins.delta < gain {
+					s.routes[ins.r] = slices.Insert(s.routes[ins.r], ins.pos, j)
+					return true
+				}
+			}
+			s.routes[r] = old
+		}
+	}
+	return false
+}
+
+// swap exchanges two jobs on different routes if that lowers the total.
+func (s *solver) swap(deadline time.Time) bool {
+	for a := range s.routes {
+		for b := a + 1; b < len(s.routes); b++ {
+			if time.Now().After(deadline) {
+				return false
+			}
+			ca, _ := s.p.cost(a, s.routes[a])
+			cb, _ := s.p.cost(b, s.routes[b])
+			for i := range s.routes[a] {
+				for k := range s.routes[b] {
+					na, nb := slices.Clone(s.routes[a]), slices.Clone(s.routes[b])
+					na[i], nb[k] = nb[k], na[i]
+					da, okA := s.p.cost(a, na)
+					db, okB := s.p.cost(b, nb)
+					if okA && okB && da+db < ca+cb {
+						s.routes[a], s.routes[b] = na, nb
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+// twoOpt reverses a stretch of one route if that lowers its cost.
+func (s *solver) twoOpt(deadline time.Time) bool {
+	for r, seq := range s.routes {
+		if time.Now().After(deadline) {
+			return false
+		}
+		base, _ := s.p.cost(r, seq)
+		for i := 0; i < len(seq); i++ {
+			for k := i + 2; k <= len(seq); k++ {
+				next := slices.Clone(seq)
+				slices.Reverse(next[i:k])
+				if c, ok := s.p.cost(r, next); ok && c < base {
+					s.routes[r] = next
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func (s *solver) solution() Solution {
+	sol := Solution{Unassigned: slices.Clone(s.unassigned)}
+	slices.Sort(sol.Unassigned)
+	for v, seq := range s.routes {
+		if len(seq) == 0 {
+			continue
+		}
+		r, _ := s.p.schedule(v, seq)
+		sol.Routes = append(sol.Routes, r)
+		sol.Travel += r.Travel
+	}
+	return sol
+}
@@ -0,0 +1,134 @@
+package vrp
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// testProblem places the depot in the middle of a 20 km square and nj jobs
+// at random in it, with travel times at 40 km/h.
+func testProblem(nj, vehicles, capacity int, seed int64) Problem {
+	rnd := rand.New(rand.NewSource(seed))
+	xs := make([][2]float64, nj+1)
+	xs[0] = [2]float64{10000, 10000}
+	for i := 1; i <= nj; i++ {
+		xs[i] = [2]float64{rnd.Float64() * 20000, rnd.Float64() * 20000}
+	}
+	p := Problem{Travel: make([][]int, nj+1)}
+	for i := range p.Travel {
+		p.Travel[i] = make([]int, nj+1)
+		for j := range p.Travel[i] {
+			p.Travel[i][j] = int(math.Hypot(xs[i][0]-xs[j][0], xs[i][1]-xs[j][1]) * 3600 / 40)
+		}
+	}
+	for range vehicles {
+		p.Vehicles = append(p.Vehicles, Vehicle{Capacity: capacity, End: 12 * 3600 * 1000})
+	}
+	for range nj {
+		p.Jobs = append(p.Jobs, Job{Demand: 1 + rnd.Intn(4), Service: 5 * 60 * 1000})
+	}
+	return p
+}
+
+// checkSolution fails the test unless every job is either on exactly one
+// feasible route or unassigned, and the totals add up.
+func checkSolution(t *testing.T, p Problem, sol Solution) {
+	t.Helper()
+	seen := map[int]bool{}
+	travel := 0
+	for _, r := range sol.Routes {
+		var seq []int
+		for _, st := range r.Stops {
+			if seen[st.Job] {
+				t.Fatalf("job %d on two routes", st.Job)
+			}
+			seen[st.Job] = true
+			seq = append(seq, st.Job)
+		}
+		want, ok := p.schedule(r.Vehicle, seq)
+		if !ok {
+			t.Fatalf("vehicle %d cannot drive %v", r.Vehicle, seq)
+		}
+		if r.Travel != want.Travel || r.Load != want.Load || r.Load > p.Vehicles[r.Vehicle].Capacity {
+			t.Fatalf("vehicle %d: travel %d load %d, schedule says %d and %d", r.Vehicle, r.Travel, r.Load, want.Travel, want.Load)
+		}
+		travel += r.Travel
+	}
+	for _, j := range sol.Unassigned {
+		if seen[j] {
+			t.Fatalf("job %d both routed and unassigned", j)
+		}
+		seen[j] = true
+	}
+	if len(seen) != len(p.Jobs) {
+		t.Fatalf("%d of %d jobs accounted for", len(seen), len(p.Jobs))
+	}
+	if travel != sol.Travel {
+		t.Fatalf("routes drive %d, solution says %d", travel, sol.Travel)
+	}
+}
+
+func TestSolveFeasible(t *testing.T) {
+	p := testProblem(120, 8, 40, 2)
+	rnd := rand.New(rand.NewSource(3))
+	for j := range p.Jobs {
+		e := rnd.Intn(6) * 3600 * 1000
+		p.Jobs[j].Earliest, p.Jobs[j].Latest = e, e+2*3600*1000
+	}
+	checkSolution(t, p, Solve(p, 5*time.Second))
+}
+
+func TestSolveSmallIsOptimal(t *testing.T) {
+	for seed := range int64(5) {
+		p := testProblem(6, 1, 100, seed)
+
+		// the cheapest order over every permutation
+		best := -1
+		jobs := []int{0, 1, 2, 3, 4, 5}
+		var permute func(k int)
+		permute = func(k int) {
+			if k == len(jobs) {
+				if c, ok := p.cost(0, jobs); ok && (best < 0 || c < best) {
+					best = c
+				}
+				return
+			}
+			for i := k; i < len(jobs); i++ {
+				jobs[k], jobs[i] = jobs[i], jobs[k]
+				permute(k + 1)
+				jobs[k], jobs[i] = jobs[i], jobs[k]
+			}
+		}
+		permute(0)
+
+		sol := Solve(p, 5*time.Second)
+		checkSolution(t, p, sol)
+		if len(sol.Unassigned) > 0 || sol.Travel != best {
+			t.Fatalf("seed %d: travel %d with %d unassigned, best order drives %d", seed, sol.Travel, len(sol.Unassigned), best)
+		}
+	}
+}
+
+func TestSolveLeavesOversizedJobs(t *testing.T) {
+	p := testProblem(10, 2, 40, 4)
+	p.Jobs[3].Demand = 41
+	sol := Solve(p, time.Second)
+	checkSolution(t, p, sol)
+	if len(sol.Unassigned) != 1 || sol.Unassigned[0] != 3 {
+		t.Fatalf("unassigned %v, want [3]", sol.Unassigned)
+	}
+}
+
+func TestSolveStopsAtDeadline(t *testing.T) {
+	p := testProblem(500, 20, 40, 3)
+	start := time.Now()
+	sol := Solve(p, 200*time.Millisecond)
+	// regret insertion alone takes seconds on this many jobs; the rest is
+	// placed by cheapest insertion once the limit has passed
+	if d := time.Since(start); d > 2*time.Second {
+		t.Fatalf("took %v with a 200ms limit", d)
+	}
+	checkSolution(t, p, sol)
+}
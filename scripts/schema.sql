@@ -1,6 +1,8 @@
 CREATE DATABASE IF NOT EXISTS routing;
 USE routing;
 
+DROP TABLE IF EXISTS turn_restrictions;
+DROP TABLE IF EXISTS traffic_profiles;
 DROP TABLE IF EXISTS edges;
 DROP TABLE IF EXISTS nodes;
 
@@ -17,8 +19,82 @@ CREATE TABLE edges (
   distance_m  INT    NOT NULL,
   speed_kmph  INT    NOT NULL,
   closed      TINYINT(1) NOT NULL DEFAULT 0,
+  -- OSM highway class, and the modes (bit 0 car, 1 bicycle, 2 foot,
+  -- 3 truck) access tags explicitly allow or deny. against_oneway edges
+  -- run against a oneway road, for modes that may do so.
+  highway         VARCHAR(32) NOT NULL DEFAULT '',
+  access_allow    TINYINT UNSIGNED NOT NULL DEFAULT 0,
+  access_deny     TINYINT UNSIGNED NOT NULL DEFAULT 0,
+  against_oneway  TINYINT(1) NOT NULL DEFAULT 0,
+  -- legal limits (meters, tonnes), 0 for none; no_hazmat forbids
+  -- hazardous goods
+  max_height  DOUBLE     NOT NULL DEFAULT 0,
+  max_weight  DOUBLE     NOT NULL DEFAULT 0,
+  max_width   DOUBLE     NOT NULL DEFAULT 0,
+  no_hazmat   TINYINT(1) NOT NULL DEFAULT 0,
+  -- traffic_profiles.profile_id with the speeds by time of week, 0 for
+  -- none
+  traffic_profile  INT NOT NULL DEFAULT 0,
   INDEX ix_src (src_node),
   INDEX ix_dst (dst_node),
   CONSTRAINT fk_src FOREIGN KEY (src_node) REFERENCES nodes(node_id),
   CONSTRAINT fk_dst FOREIGN KEY (dst_node) REFERENCES nodes(node_id)
 ) ENGINE=InnoDB;
+
+-- OSM turn restriction relations, resolved to the edge entering and the
+-- edge leaving the via node. type is the OSM value, e.g. no_left_turn or
+-- only_straight_on.
+CREATE TABLE turn_restrictions (
+  from_edge  BIGINT      NOT NULL,
+  via_node   BIGINT      NOT NULL,
+  to_edge    BIGINT      NOT NULL,
+  type       VARCHAR(32) NOT NULL,
+  PRIMARY KEY (from_edge, to_edge),
+  CONSTRAINT fk_from_edge FOREIGN KEY (from_edge) REFERENCES edges(edge_id),
+  CONSTRAINT fk_to_edge   FOREIGN KEY (to_edge)   REFERENCES edges(edge_id),
+  CONSTRAINT fk_via       FOREIGN KEY (via_node)  REFERENCES nodes(node_id)
+) ENGINE=InnoDB;
+
+-- Speeds by time of week, shared by the edges whose traffic_profile names
+-- them. bucket is the 15 minute slot of the week in the server's
+-- -traffic-tz zone: 0 is Monday 00:00, 671 is Sunday 23:45. Buckets
+-- without a row use the edge's speed_kmph, and no bucket is faster than
+-- it.
+CREATE TABLE traffic_profiles (
+  profile_id  INT      NOT NULL,
+  bucket      SMALLINT NOT NULL,
+  speed_kmph  INT      NOT NULL,
+  PRIMARY KEY (profile_id, bucket)
+) ENGINE=InnoDB;
+
+-- Contraction hierarchy per metric, written by internal/ch. Nodes are
+-- listed by contraction rank; arcs are original edges (child1 = -1) or
+-- shortcuts that point at the two arcs they replace. ch_meta holds the
+-- fingerprint of the edges it was built from, which the server checks
+-- against the edges table at startup.
+DROP TABLE IF EXISTS ch_meta;
+DROP TABLE IF EXISTS ch_arcs;
+DROP TABLE IF EXISTS ch_nodes;
+
+CREATE TABLE ch_meta (
+  metric  VARCHAR(16)     NOT NULL PRIMARY KEY,
+  edges   BIGINT UNSIGNED NOT NULL
+) ENGINE=InnoDB;
+
+CREATE TABLE ch_nodes (
+  metric     VARCHAR(16) NOT NULL,
+  node_rank  INT         NOT NULL,
+  node_id    BIGINT      NOT NULL,
+  PRIMARY KEY (metric, node_rank)
+) ENGINE=InnoDB;
+
+CREATE TABLE ch_arcs (
+  metric    VARCHAR(16) NOT NULL,
+  arc_id    INT         NOT NULL,
+  src_node  BIGINT      NOT NULL,
+  dst_node  BIGINT      NOT NULL,
+  cost      BIGINT      NOT NULL,
+  child1    INT         NOT NULL DEFAULT -1,
+  child2    INT         NOT NULL DEFAULT -1,
+  PRIMARY KEY (metric, arc_id)
+) ENGINE=InnoDB;
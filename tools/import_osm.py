@@ -14,23 +14,121 @@ def haversine(lat1, lon1, lat2, lon2):
     a = math.sin(dphi/2)**2 + math.cos(phi1)*math.cos(phi2)*math.sin(dlambda/2)**2
     return int(R * 2 * math.atan2(math.sqrt(a), math.sqrt(1-a)))
 
+# typical speed (km/h) per highway class when there is no maxspeed tag;
+# profiles cap these further for slower modes
+CLASS_SPEEDS = {
+    "motorway": 100, "motorway_link": 60,
+    "trunk": 80, "trunk_link": 50,
+    "primary": 60, "primary_link": 40,
+    "secondary": 50, "secondary_link": 40,
+    "tertiary": 40, "tertiary_link": 30,
+    "unclassified": 30, "residential": 30, "road": 30,
+    "living_street": 10, "service": 15,
+    "cycleway": 18, "path": 12, "track": 12, "bridleway": 8,
+    "footway": 5, "pedestrian": 5, "steps": 3,
+}
+
 def speed_from_tags(tags):
     if "maxspeed" in tags:
         try:
             return int("".join(c for c in tags["maxspeed"] if c.isdigit()))
         except:
             pass
-    hw = tags.get("highway", "")
-    if hw in ("motorway", "trunk"): return 90
-    if hw in ("primary", "secondary", "tertiary"): return 60
-    return 40
+    return CLASS_SPEEDS.get(tags.get("highway", ""), 40)
+
+# travel modes, as bits of edges.access_allow / access_deny
+MODE_CAR, MODE_BICYCLE, MODE_FOOT, MODE_TRUCK = 1, 2, 4, 8
+ALL_MODES = MODE_CAR | MODE_BICYCLE | MODE_FOOT | MODE_TRUCK
+
+# OSM access keys from most general to most specific, so later ones win.
+# The general keys only ever forbid: access=yes on a motorway doesn't open
+# it to pedestrians.
+ACCESS_KEYS = [
+    ("access", ALL_MODES, False),
+    ("vehicle", MODE_CAR | MODE_BICYCLE | MODE_TRUCK, False),
+    ("motor_vehicle", MODE_CAR | MODE_TRUCK, True),
+    ("motorcar", MODE_CAR, True),
+    ("hgv", MODE_TRUCK, True),
+    ("bicycle", MODE_BICYCLE, True),
+    ("foot", MODE_FOOT, True),
+]
+ACCESS_YES = ("yes", "designated", "permissive", "destination")
+ACCESS_NO = ("no", "private")
+
+def access_from_tags(tags):
+    allow = deny = 0
+    for key, modes, grants in ACCESS_KEYS:
+        v = tags.get(key)
+        if v in ACCESS_YES:
+            deny &= ~modes
+            if grants:
+                allow |= modes
+        elif v in ACCESS_NO:
+            deny |= modes
+            allow &= ~modes
+    return allow, deny
+
+# parse an OSM length limit (maxheight, maxwidth) into meters, 0 for none
+# or unparseable. Accepts "4.5", "4.5 m" and feet/inches like 14'6".
+def meters_from_tag(v):
+    if not v:
+        return 0.0
+    v = v.strip().replace(",", ".")
+    try:
+        if "'" in v:
+            feet, _, rest = v.partition("'")
+            inches = rest.replace('"', "").strip() or "0"
+            return round(float(feet) * 0.3048 + float(inches) * 0.0254, 2)
+        return float(v.replace("m", "").strip())
+    except ValueError:
+        return 0.0
+
+# parse an OSM maxweight into tonnes, 0 for none or unparseable. Accepts
+# "7.5", "7.5 t", "7500 kg" and "st" (short tons).
+def tonnes_from_tag(v):
+    if not v:
+        return 0.0
+    v = v.strip().replace(",", ".")
+    try:
+        if v.endswith("kg"):
+            return float(v[:-2].strip()) / 1000
+        if v.endswith("st"):
+            return round(float(v[:-2].strip()) * 0.90718, 2)
+        return float(v.rstrip("t").strip())
+    except ValueError:
+        return 0.0
+
+def limits_from_tags(tags):
+    return (
+        meters_from_tag(tags.get("maxheight")),
+        tonnes_from_tag(tags.get("maxweight")),
+        meters_from_tag(tags.get("maxwidth")),
+        int(tags.get("hazmat") == "no"),
+    )
+
+# 1 for oneway along the way, -1 against it, 0 for two-way
+def oneway_from_tags(tags):
+    v = tags.get("oneway")
+    if v in ("yes", "true", "1"):
+        return 1
+    if v == "-1":
+        return -1
+    if v == "no":
+        return 0
+    if tags.get("junction") == "roundabout" or tags.get("highway") in ("motorway", "motorway_link"):
+        return 1
+    return 0
 
 
 class OSMHandler(osmium.SimpleHandler):
     def __init__(self):
         super().__init__()
         self.nodes = {}  # id -> (lat, lon)
-        self.edges = []  # (src, dst, dist_m, speed)
+        # (edge_id, src, dst, dist_m, speed, highway, allow, deny, against_oneway,
+        #  max_height, max_weight, max_width, no_hazmat)
+        self.edges = []
+        self.way_edges = {}  # way id -> [(edge_id, src, dst)]
+        self.restrictions = []  # (from_edge, via_node, to_edge, type)
 
     def node(self, n):
         self.nodes[n.id] = (n.location.lat, n.location.lon)
@@ -43,7 +141,15 @@ class OSMHandler(osmium.SimpleHandler):
         if len(refs) < 2:
             return
 
-        speed = speed_from_tags(dict(w.tags))
+        tags = dict(w.tags)
+        speed = speed_from_tags(tags)
+        highway = tags["highway"]
+        allow, deny = access_from_tags(tags)
+        oneway = oneway_from_tags(tags)
+        # modes that may ride against the oneway
+        contra = MODE_BICYCLE if tags.get("oneway:bicycle") == "no" else 0
+        limits = limits_from_tags(tags)
+        segs = self.way_edges.setdefault(w.id, [])
 
         for i in range(len(refs)-1):
             a = refs[i]
@@ -53,12 +159,49 @@ class OSMHandler(osmium.SimpleHandler):
                 lat2, lon2 = self.nodes[b]
                 dist = haversine(lat1, lon1, lat2, lon2)
 
-                # forward edge
-                self.edges.append((a, b, dist, speed))
+                # both directions are stored; the one against a oneway is
+                # flagged so that only profiles ignoring oneways use it
+                fwd = (allow, deny, False)
+                bwd = (allow, deny, False)
+                if oneway == 1:
+                    bwd = (contra, deny, True)
+                elif oneway == -1:
+                    fwd = (contra, deny, True)
+                self.add_edge(segs, a, b, dist, speed, highway, *fwd, limits)
+                self.add_edge(segs, b, a, dist, speed, highway, *bwd, limits)
+
+    def add_edge(self, segs, a, b, dist, speed, highway, allow, deny, against, limits):
+        # edge ids are assigned here, not by AUTO_INCREMENT, so that turn
+        # restrictions can refer to them
+        eid = len(self.edges) + 1
+        self.edges.append((eid, a, b, dist, speed, highway, allow, deny, int(against), *limits))
+        segs.append((eid, a, b))
+
+    def relation(self, r):
+        # PBF files list relations after ways, so way_edges is complete here
+        if r.tags.get("type") != "restriction":
+            return
+        kind = r.tags.get("restriction")
+        if not kind:
+            return
+
+        from_way = to_way = via = None
+        for m in r.members:
+            if m.type == "w" and m.role == "from":
+                from_way = m.ref
+            elif m.type == "w" and m.role == "to":
+                to_way = m.ref
+            elif m.type == "n" and m.role == "via":
+                via = m.ref
+        # via-way restrictions are not supported
+        if from_way is None or to_way is None or via is None:
+            return
 
-                # backward for two-way roads
-                if w.tags.get("oneway", "no") == "no":
-                    self.edges.append((b, a, dist, speed))
+        into = [e for e, _, dst in self.way_edges.get(from_way, []) if dst == via]
+        out = [e for e, src, _ in self.way_edges.get(to_way, []) if src == via]
+        for f in into:
+            for t in out:
+                self.restrictions.append((f, via, t, kind))
 
 
 def import_to_mysql(pbf_path, host, user, password, dbname):
@@ -68,6 +211,7 @@ def import_to_mysql(pbf_path, host, user, password, dbname):
 
     print(f"Nodes: {len(handler.nodes)}")
     print(f"Edges: {len(handler.edges)}")
+    print(f"Turn restrictions: {len(handler.restrictions)}")
 
     conn = mysql.connector.connect(
         host=host, user=user, password=password, database=dbname
@@ -75,6 +219,7 @@ def import_to_mysql(pbf_path, host, user, password, dbname):
     cur = conn.cursor()
 
     print("Clearing old data…")
+    cur.execute("DELETE FROM turn_restrictions")
     cur.execute("DELETE FROM edges")
     cur.execute("DELETE FROM nodes")
     conn.commit()
@@ -94,8 +239,10 @@ def import_to_mysql(pbf_path, host, user, password, dbname):
 
     print("Inserting edges…")
     esql = """INSERT INTO edges
-              (src_node, dst_node, distance_m, speed_kmph, closed)
-              VALUES (%s, %s, %s, %s, 0)"""
+              (edge_id, src_node, dst_node, distance_m, speed_kmph, closed,
+               highway, access_allow, access_deny, against_oneway,
+               max_height, max_weight, max_width, no_hazmat)
+              VALUES (%s, %s, %s, %s, %s, 0, %s, %s, %s, %s, %s, %s, %s, %s)"""
     batch = []
     for e in handler.edges:
         batch.append(e)
@@ -107,6 +254,22 @@ def import_to_mysql(pbf_path, host, user, password, dbname):
         cur.executemany(esql, batch)
         conn.commit()
 
+    print("Inserting turn restrictions…")
+    # INSERT IGNORE: overlapping relations can restrict the same edge pair
+    tsql = """INSERT IGNORE INTO turn_restrictions
+              (from_edge, via_node, to_edge, type)
+              VALUES (%s, %s, %s, %s)"""
+    batch = []
+    for t in handler.restrictions:
+        batch.append(t)
+        if len(batch) >= 5000:
+            cur.executemany(tsql, batch)
+            conn.commit()
+            batch.clear()
+    if batch:
+        cur.executemany(tsql, batch)
+        conn.commit()
+
     print("✅ Import finished")
     cur.close()
     conn.close()